@@ -11,9 +11,19 @@ type ServerConfig struct {
 	LogConfig   *LogConfig    `mapstructure:"log"`
 }
 
+// Kubeconfig 集群的连接配置，按以下优先级选择凭据来源：
+// inCluster: 服务部署在集群内时使用ServiceAccount
+// server: 直接配置apiserver地址及token、CA证书
+// path: kubeconfig文件，可通过context指定使用的上下文，path为空时使用KUBECONFIG环境变量或~/.kube/config
 type Kubeconfig struct {
-	Name string `mapstructure:"name"`
-	Path string `mapstructure:"path"`
+	Name      string `mapstructure:"name"`
+	Path      string `mapstructure:"path"`
+	Context   string `mapstructure:"context"`
+	InCluster bool   `mapstructure:"inCluster"`
+	Server    string `mapstructure:"server"`
+	Token     string `mapstructure:"token"`
+	CAData    string `mapstructure:"caData"`   // base64编码的CA证书，与kubeconfig中的certificate-authority-data一致
+	Insecure  bool   `mapstructure:"insecure"` // 跳过apiserver证书校验，仅用于测试环境
 }
 
 type MysqlConfig struct {
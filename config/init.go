@@ -5,10 +5,33 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"sync"
 )
 
-// Conf 全局变量，存放配置信息
-var Conf = new(ServerConfig)
+var (
+	// conf 存放配置信息，配置文件变更时会在监听协程中整体替换，需要通过Get读取
+	conf = new(ServerConfig)
+	// confMu 保护conf的读写
+	confMu sync.RWMutex
+	// changeHooks 配置文件变更后的回调，用于集群client、日志级别等配置的热加载
+	changeHooks []func(oldConf, newConf *ServerConfig)
+	// changeMu 保证同一时间只有一次配置变更在处理，编辑器保存文件时可能连续触发多次事件
+	changeMu sync.Mutex
+)
+
+// Get 获取当前生效的配置，配置热加载时返回的是整体替换后的新对象，调用方不要修改返回值
+func Get() *ServerConfig {
+	confMu.RLock()
+	defer confMu.RUnlock()
+	return conf
+}
+
+// OnChange 注册配置变更的回调，回调中可以通过对比新旧配置决定需要重新加载的内容
+func OnChange(fn func(oldConf, newConf *ServerConfig)) {
+	changeMu.Lock()
+	defer changeMu.Unlock()
+	changeHooks = append(changeHooks, fn)
+}
 
 // GetEnvInfo 获取环境变量
 func GetEnvInfo(env string) bool {
@@ -43,17 +66,34 @@ func Init() {
 		}
 	}
 
-	// 将读取的配置信息保存至全局变量Conf
-	if err := v.Unmarshal(Conf); err != nil {
+	// 将读取的配置信息保存至全局变量conf
+	newConf := new(ServerConfig)
+	if err := v.Unmarshal(newConf); err != nil {
 		panic(fmt.Errorf("unmarshal conf failed, %v\n", err))
 	}
+	confMu.Lock()
+	conf = newConf
+	confMu.Unlock()
 
 	// 监听配置文件是否发生改变
 	v.WatchConfig()
-	// 如果发生了改变，需要同步给全局变量Conf
+	// 如果发生了改变，需要同步给全局变量conf
+	// 先解析到新的对象中再整体替换，解析失败时保留旧配置，回调中可以拿到变更前的配置进行对比
 	v.OnConfigChange(func(in fsnotify.Event) {
-		if err := v.Unmarshal(Conf); err != nil {
-			panic(fmt.Errorf("unmarshal conf failed, err:%s \n", err))
+		newConf := new(ServerConfig)
+		if err := v.Unmarshal(newConf); err != nil {
+			zap.L().Error(fmt.Sprintf("unmarshal conf failed, 保留原有配置, err: %v", err))
+			return
+		}
+		changeMu.Lock()
+		defer changeMu.Unlock()
+		confMu.Lock()
+		oldConf := conf
+		conf = newConf
+		confMu.Unlock()
+		zap.L().Info("config file changed", zap.String("file", in.Name))
+		for _, fn := range changeHooks {
+			fn(oldConf, newConf)
 		}
 	})
 }
\ No newline at end of file
@@ -26,16 +26,12 @@ func (a *allRes) GetAllNumHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
-	data, errs := service.AllRes.GetAllNum(client)
+	data, errs := service.AllRes.GetAllNum(cc)
 	if len(errs) > 0 {
 		fmt.Printf("绑定参数失败, %v\n", err.Error())
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"k8sManagerApi/service"
+	"net/http"
+)
+
+var Apply apply
+
+type apply struct{}
+
+// ApplyHandler 使用server-side apply应用多文档manifest，返回每个资源的created、configured、unchanged、failed结果
+func (a *apply) ApplyHandler(ctx *gin.Context) {
+	params := new(struct {
+		Cluster      string `json:"cluster"`
+		Namespace    string `json:"namespace"` // manifest中未指定namespace的资源使用，为空时为default
+		Content      string `json:"content"`   // json或多文档yaml
+		FieldManager string `json:"field_manager"`
+		Force        bool   `json:"force"`
+		DryRun       bool   `json:"dry_run"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Apply.Apply(cc, params.Namespace, params.Content, params.FieldManager, params.Force, params.DryRun)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	msg := "success, apply完成"
+	if params.DryRun {
+		msg = "success, apply预检完成(dry run)"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("%s, 失败%d个", msg, data.Counts[service.ApplyFailed]),
+		"data": data,
+	})
+}
\ No newline at end of file
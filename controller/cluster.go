@@ -3,9 +3,10 @@ package controller
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
 	"k8sManagerApi/service"
 	"net/http"
-	"sort"
 )
 
 var Cluster cluster
@@ -14,15 +15,160 @@ type cluster struct{}
 
 // GetClustersHandler 获取集群列表
 func (c *cluster) GetClustersHandler(ctx *gin.Context) {
-	list := make([]string, 0)
-	for key := range service.K8s.ClientMap {
-		list = append(list, key)
-	}
-	fmt.Printf("list:====> %T\n", list)
-	sort.Strings(list)
+	list := service.K8s.ListClusters()
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取集群列表成功",
 		"data": list,
 	})
+}
+
+// GetClusterStatusHandler 获取集群的健康状态，包括版本、探测耗时及最近一次错误
+func (c *cluster) GetClusterStatusHandler(ctx *gin.Context) {
+	data := service.ClusterHealth.GetStatusList()
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取集群状态成功",
+		"data": data,
+	})
+}
+
+// GetClusterCacheHandler 获取集群informer缓存的同步状态
+func (c *cluster) GetClusterCacheHandler(ctx *gin.Context) {
+	data := service.K8s.GetCacheStatusList()
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取集群缓存同步状态成功",
+		"data": data,
+	})
+}
+
+// GetClusterCapabilitiesHandler 获取集群的版本、提供的API组及版本、已安装的CRD
+func (c *cluster) GetClusterCapabilitiesHandler(ctx *gin.Context) {
+	params := new(struct {
+		Cluster string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Capability.GetCapabilities(cc)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取集群能力信息成功",
+		"data": data,
+	})
+}
+
+// CreateClusterHandler 注册集群，form表单上传kubeconfig文件
+func (c *cluster) CreateClusterHandler(ctx *gin.Context) {
+	data, ok := c.bindClusterForm(ctx)
+	if !ok {
+		return
+	}
+	if err := service.Cluster.CreateCluster(data); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 注册集群成功",
+		"data": nil,
+	})
+}
+
+// UpdateClusterHandler 更新集群的kubeconfig
+func (c *cluster) UpdateClusterHandler(ctx *gin.Context) {
+	data, ok := c.bindClusterForm(ctx)
+	if !ok {
+		return
+	}
+	if err := service.Cluster.UpdateCluster(data); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 更新集群成功",
+		"data": nil,
+	})
+}
+
+// DeleteClusterHandler 删除集群
+func (c *cluster) DeleteClusterHandler(ctx *gin.Context) {
+	params := new(struct {
+		Name string `json:"name"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	if err := service.Cluster.DeleteCluster(params.Name); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 删除集群成功",
+		"data": nil,
+	})
+}
+
+// bindClusterForm 绑定集群名、描述，并读取上传的kubeconfig文件内容
+func (c *cluster) bindClusterForm(ctx *gin.Context) (*service.ClusterCreate, bool) {
+	data := new(service.ClusterCreate)
+	if err := ctx.ShouldBind(data); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return nil, false
+	}
+	file, _, err := ctx.Request.FormFile("kubeconfig")
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取上传信息失败, %v", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "获取上传信息失败" + err.Error(),
+			"data": nil,
+		})
+		return nil, false
+	}
+	defer file.Close()
+	data.KubeConfig, err = io.ReadAll(file)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("读取kubeconfig文件失败, %v", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "读取kubeconfig文件失败" + err.Error(),
+			"data": nil,
+		})
+		return nil, false
+	}
+	return data, true
 }
\ No newline at end of file
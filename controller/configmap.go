@@ -17,11 +17,10 @@ func (c *configMap) GetConfigMapsHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace   string `form:"namespace"`
+		Cluster     string `form:"cluster"`
+		AllClusters bool   `form:"all_clusters"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -33,7 +32,7 @@ func (c *configMap) GetConfigMapsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -42,25 +41,40 @@ func (c *configMap) GetConfigMapsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.ConfigMap.GetConfigMaps(client, params.Namespace, params.FilterName, params.Limit, params.Page)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
+	if params.AllClusters {
+		data, err := service.ConfigMap.GetConfigMapsAllClusters(params.Namespace, query)
+		if err != nil {
+			serviceError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"code": http.StatusOK,
+			"msg":  "success, 跨集群获取ConfigMap列表成功",
+			"data": params.view(data),
 		})
 		return
 	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.ConfigMap.GetConfigMaps(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取ConfigMap列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
 // GetConfigMapDetailHandler 获取configMap详情
 func (c *configMap) GetConfigMapDetailHandler(ctx *gin.Context) {
 	params := new(struct {
+		detailParams
 		Namespace     string `form:"namespace"`
 		ConfigmapName string `form:"configmap_name"`
 		Cluster       string `form:"cluster"`
@@ -76,26 +90,23 @@ func (c *configMap) GetConfigMapDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.ConfigMap.GetConfigMapDetail(client, params.Namespace, params.ConfigmapName)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取ConfigMap详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -117,21 +128,13 @@ func (c *configMap) UpdateConfigMapHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.ConfigMap.UpdateConfigMap(client, params.Namespace, params.Content)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新ConfigMap失败, %v", err.Error()))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -158,21 +161,13 @@ func (c *configMap) DeleteConfigMapHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.ConfigMap.DeleteConfigMap(client, params.Namespace, params.ConfigmapName)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除ConfigMap失败, %v", err.Error()))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"k8sManagerApi/service"
+	"net/http"
+)
+
+var CRD crd
+
+type crd struct{}
+
+// customResourceParams 自定义资源接口的资源参数，crd为CRD的名称，如certificates.cert-manager.io
+type customResourceParams struct {
+	CRD     string `form:"crd" json:"crd"`
+	Version string `form:"version" json:"version"`
+	Cluster string `form:"cluster" json:"cluster"`
+}
+
+// GetCRDsHandler 获取CRD列表，支持过滤、排序、分页
+func (c *crd) GetCRDsHandler(ctx *gin.Context) {
+	params := new(struct {
+		listParams
+		Cluster string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	query, err := params.query()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClientFor(params.Cluster, service.CRDGVR)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.CRD.GetCRDs(cc, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取CRD列表成功",
+		"data": data,
+	})
+}
+
+// GetCRDDetailHandler 获取CRD详情，包含各版本的schema及打印列
+func (c *crd) GetCRDDetailHandler(ctx *gin.Context) {
+	params := new(struct {
+		Name    string `form:"name"`
+		Cluster string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.CRD.GetCRDDetail(cc, params.Name)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取CRD详情成功",
+		"data": data,
+	})
+}
+
+// GetCustomResourcesHandler 获取自定义资源列表，namespace为空时获取所有namespace
+func (c *crd) GetCustomResourcesHandler(ctx *gin.Context) {
+	params := new(struct {
+		customResourceParams
+		listParams
+		Namespace string `form:"namespace"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	query, err := params.query()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.CRD.GetCustomResources(cc, params.CRD, params.Version, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取自定义资源列表成功",
+		"data": data,
+	})
+}
+
+// GetCustomResourceDetailHandler 获取自定义资源详情
+func (c *crd) GetCustomResourceDetailHandler(ctx *gin.Context) {
+	params := new(struct {
+		detailParams
+		customResourceParams
+		Name      string `form:"name"`
+		Namespace string `form:"namespace"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := c.mapping(ctx, &params.customResourceParams)
+	if !ok {
+		return
+	}
+	data, err := service.Generic.GetResourceDetail(cc, mapping, params.Namespace, params.Name)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取自定义资源详情成功",
+		"data": content,
+	})
+}
+
+// UpdateCustomResourceHandler 更新自定义资源，content为资源的json或yaml
+func (c *crd) UpdateCustomResourceHandler(ctx *gin.Context) {
+	params := new(struct {
+		customResourceParams
+		Namespace string `json:"namespace"`
+		Content   string `json:"content"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := c.mapping(ctx, &params.customResourceParams)
+	if !ok {
+		return
+	}
+	if err := service.Generic.UpdateResource(cc, mapping, params.Namespace, params.Content); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 更新自定义资源成功",
+		"data": nil,
+	})
+}
+
+// DeleteCustomResourceHandler 删除自定义资源
+func (c *crd) DeleteCustomResourceHandler(ctx *gin.Context) {
+	params := new(struct {
+		customResourceParams
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := c.mapping(ctx, &params.customResourceParams)
+	if !ok {
+		return
+	}
+	if err := service.Generic.DeleteResource(cc, mapping, params.Namespace, params.Name); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 删除自定义资源成功",
+		"data": nil,
+	})
+}
+
+// mapping 获取集群客户端，并根据CRD解析自定义资源，集群不存在时返回400，不可达时返回503，未安装CRD或版本不存在时返回404
+func (c *crd) mapping(ctx *gin.Context, params *customResourceParams) (*service.ClusterClient, *service.ResourceMapping, bool) {
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return nil, nil, false
+	}
+	mapping, _, err := service.CRD.Mapping(cc, params.CRD, params.Version)
+	if err != nil {
+		serviceError(ctx, err)
+		return nil, nil, false
+	}
+	return cc, mapping, true
+}
\ No newline at end of file
@@ -17,11 +17,10 @@ func (d *daemonSet) GetDaemonSetsHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace   string `form:"namespace"`
+		Cluster     string `form:"cluster"`
+		AllClusters bool   `form:"all_clusters"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -33,7 +32,7 @@ func (d *daemonSet) GetDaemonSetsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -42,19 +41,33 @@ func (d *daemonSet) GetDaemonSetsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.DaemonSet.GetDaemonSets(client, params.FilterName, params.Namespace, params.Limit, params.Page)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
+	if params.AllClusters {
+		data, err := service.DaemonSet.GetDaemonSetsAllClusters(params.Namespace, query)
+		if err != nil {
+			serviceError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"code": http.StatusOK,
+			"msg":  "success, 跨集群获取DaemonSet列表成功",
+			"data": params.view(data),
 		})
 		return
 	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.DaemonSet.GetDaemonSets(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取DaemonSet列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -63,6 +76,7 @@ func (d *daemonSet) GetDaemonSetDetailHandler(ctx *gin.Context) {
 	//    处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		DaemonSetName string `form:"daemonSet_name"`
 		Namespace     string `form:"namespace"`
 		Cluster       string `form:"cluster"`
@@ -79,26 +93,23 @@ func (d *daemonSet) GetDaemonSetDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.DaemonSet.GetDaemonSetDetail(client, params.DaemonSetName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取DaemonSet详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -121,25 +132,51 @@ func (d *daemonSet) DeleteDaemonSetHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行删除
 	if err := service.DaemonSet.DeleteDaemonSet(client, params.DaemonSetName, params.Namespace); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 删除DaemonSet成功",
+		"data": nil,
+	})
+}
+
+// RestartDaemonSetHandler 重启DaemonSet
+func (d *daemonSet) RestartDaemonSetHandler(ctx *gin.Context) {
+	params := new(struct {
+		DaemonSetName string `json:"daemonSet_name"`
+		Namespace     string `json:"namespace"`
+		Cluster       string `json:"cluster"`
+	})
+	// form格式使用Bind方法，json格式使用SholdBindJson方法
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	// 调用Service层方法进行重启
+	if err := service.DaemonSet.RestartDaemonSet(client, params.DaemonSetName, params.Namespace); err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 删除DaemonSet成功",
+		"msg":  "success, 重启DaemonSet成功",
 		"data": nil,
 	})
 }
@@ -163,20 +200,12 @@ func (d *daemonSet) UpdateDaemonSetHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.DaemonSet.UpdateDaemonSet(client, params.Namespace, params.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -203,20 +232,12 @@ func (d *daemonSet) CreateDaemonSetHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(daemonSetCreate.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行创建
 	if err = service.DaemonSet.CreateDaemonSet(client, daemonSetCreate); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -240,22 +261,14 @@ func (d *daemonSet) GetDaemonSetNumPerNpHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
-	data, err := service.DaemonSet.GetDaemonSetNumPerNp(client)
+	data, err := service.DaemonSet.GetDaemonSetNumPerNp(cc)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
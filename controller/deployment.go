@@ -17,11 +17,10 @@ func (d *deployment) GetDeploymentsHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace   string `form:"namespace"`
+		Cluster     string `form:"cluster"`
+		AllClusters bool   `form:"all_clusters"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -33,7 +32,7 @@ func (d *deployment) GetDeploymentsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -42,19 +41,33 @@ func (d *deployment) GetDeploymentsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.Deployment.GetDeployments(client, params.FilterName, params.Namespace, params.Limit, params.Page)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
+	if params.AllClusters {
+		data, err := service.Deployment.GetDeploymentsAllClusters(params.Namespace, query)
+		if err != nil {
+			serviceError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"code": http.StatusOK,
+			"msg":  "success, 跨集群获取Deployment列表成功",
+			"data": params.view(data),
 		})
 		return
 	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Deployment.GetDeployments(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Deployment列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -63,6 +76,7 @@ func (d *deployment) GetDeploymentDetailHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		DeploymentName string `form:"deployment_name"`
 		Namespace      string `form:"namespace"`
 		Cluster        string `form:"cluster"`
@@ -79,26 +93,23 @@ func (d *deployment) GetDeploymentDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.Deployment.GetDeploymentDetail(client, params.DeploymentName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Deployment详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -122,21 +133,13 @@ func (d *deployment) SetDeploymentReplicasHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用方法进行更新
 	Replicas, err := service.Deployment.SetDeploymentReplicas(client, params.DeploymentName, params.Namespace, params.Replicas)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -164,20 +167,12 @@ func (d *deployment) CreateDeploymentHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(deployCreate.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行创建
 	if err = service.Deployment.CreateDeployment(client, deployCreate); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -206,29 +201,12 @@ func (d *deployment) DeleteDeploymentHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
-	//// 先调用Service层中的删除数据库中的Pod方法，进行删除数据库中的Pod信息
-	//if err := service.Pod.DelPodByDeployment(client, params.Cluster, params.DeploymentName, params.Namespace); err != nil {
-	//	ctx.JSON(http.StatusInternalServerError, gin.H{
-	//		"code": http.StatusInternalServerError,
-	//		"msg":  "failed, 删除数据库中的deployment资源的Pod失败",
-	//		"data": nil,
-	//	})
-	//	return
-	//}
-	// 再调用Service层方法进行删除集群中的资源
+	// 调用Service层方法进行删除集群中的资源，Pod的删除时间由informer记录
 	if err := service.Deployment.DeleteDeployment(client, params.DeploymentName, params.Namespace); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -257,20 +235,12 @@ func (d *deployment) RestartDeploymentHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行重启
 	if err := service.Deployment.RestartDeployment(client, params.DeploymentName, params.Namespace); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -299,20 +269,12 @@ func (d *deployment) UpdateDeploymentHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.Deployment.UpdateDeployment(client, params.Namespace, params.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -336,27 +298,120 @@ func (d *deployment) GetDeployNumPerNpHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Deployment.GetDeployNumPerNp(cc)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取每个namespace的deployment数量成功",
+		"data": data,
+	})
+}
+
+// GetDeploymentRevisionsHandler 获取Deployment的历史版本
+func (d *deployment) GetDeploymentRevisionsHandler(ctx *gin.Context) {
+	params := new(struct {
+		DeploymentName string `form:"deployment_name"`
+		Namespace      string `form:"namespace"`
+		Cluster        string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
-	data, err := service.Deployment.GetDeployNumPerNp(client)
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Deployment.GetDeploymentRevisions(client, params.DeploymentName, params.Namespace)
 	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取Deployment历史版本成功",
+		"data": data,
+	})
+}
+
+// GetDeploymentRevisionDiffHandler 对比Deployment两个版本的Pod模板，to为空时与当前版本对比
+func (d *deployment) GetDeploymentRevisionDiffHandler(ctx *gin.Context) {
+	params := new(struct {
+		DeploymentName string `form:"deployment_name"`
+		Namespace      string `form:"namespace"`
+		From           int64  `form:"from" binding:"required"`
+		To             int64  `form:"to"`
+		Cluster        string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Deployment.DiffDeploymentRevisions(client, params.DeploymentName, params.Namespace, params.From, params.To)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 获取每个namespace的deployment数量成功",
+		"msg":  "success, 对比Deployment版本成功",
 		"data": data,
 	})
+}
+
+// RollbackDeploymentHandler 回滚Deployment，revision为0时回滚到上一个版本
+func (d *deployment) RollbackDeploymentHandler(ctx *gin.Context) {
+	params := new(struct {
+		DeploymentName string `json:"deployment_name"`
+		Namespace      string `json:"namespace"`
+		Revision       int64  `json:"revision"`
+		Cluster        string `json:"cluster"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	if err := service.Deployment.RollbackDeployment(client, params.DeploymentName, params.Namespace, params.Revision); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 回滚Deployment成功",
+		"data": nil,
+	})
 }
\ No newline at end of file
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"k8sManagerApi/service"
+	"net/http"
+)
+
+// serviceError 返回service层的错误，apiserver的错误按原因返回409、404、403、422等状态码，data中为原因及校验失败的字段
+// 集群不支持请求的资源时返回404，其它错误返回500
+func serviceError(ctx *gin.Context, err error) {
+	if errors.Is(err, service.ErrNotSupported) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	var apiErr *service.APIError
+	if !errors.As(err, &apiErr) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	ctx.JSON(apiErr.Code, gin.H{
+		"code": apiErr.Code,
+		"msg":  err.Error(),
+		"data": apiErr,
+	})
+}
+
+// clusterError 返回获取集群客户端的错误，集群不可达时为503，集群不支持请求的资源时为404，集群不存在等其它错误返回400
+func clusterError(ctx *gin.Context, err error) {
+	var apiErr *service.APIError
+	if errors.Is(err, service.ErrNotSupported) || errors.As(err, &apiErr) {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"code": http.StatusBadRequest,
+		"msg":  err.Error(),
+		"data": nil,
+	})
+}
\ No newline at end of file
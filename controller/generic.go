@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"k8sManagerApi/service"
+	"net/http"
+)
+
+var Generic generic
+
+type generic struct{}
+
+// resourceParams 通用资源接口的资源参数，group为空时是core组，version为空时使用集群的首选版本
+type resourceParams struct {
+	Group    string `form:"group" json:"group"`
+	Version  string `form:"version" json:"version"`
+	Resource string `form:"resource" json:"resource"`
+	Cluster  string `form:"cluster" json:"cluster"`
+}
+
+// GetResourcesHandler 获取任意资源的列表，支持过滤、排序、分页
+func (g *generic) GetResourcesHandler(ctx *gin.Context) {
+	params := new(struct {
+		resourceParams
+		listParams
+		Namespace string `form:"namespace"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	query, err := params.query()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := g.resolve(ctx, &params.resourceParams)
+	if !ok {
+		return
+	}
+	data, err := service.Generic.GetResources(cc, mapping, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 获取%s列表成功", mapping.Kind),
+		"data": params.view(data),
+	})
+}
+
+// GetResourceDetailHandler 获取任意资源的详情
+func (g *generic) GetResourceDetailHandler(ctx *gin.Context) {
+	params := new(struct {
+		detailParams
+		resourceParams
+		Name      string `form:"name"`
+		Namespace string `form:"namespace"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := g.resolve(ctx, &params.resourceParams)
+	if !ok {
+		return
+	}
+	data, err := service.Generic.GetResourceDetail(cc, mapping, params.Namespace, params.Name)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 获取%s详情成功", mapping.Kind),
+		"data": content,
+	})
+}
+
+// CreateResourceHandler 创建任意资源，content为资源的json或yaml
+func (g *generic) CreateResourceHandler(ctx *gin.Context) {
+	params := new(struct {
+		resourceParams
+		Namespace string `json:"namespace"`
+		Content   string `json:"content"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := g.resolve(ctx, &params.resourceParams)
+	if !ok {
+		return
+	}
+	if err := service.Generic.CreateResource(cc, mapping, params.Namespace, params.Content); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 创建%s成功", mapping.Kind),
+		"data": nil,
+	})
+}
+
+// UpdateResourceHandler 更新任意资源，content为资源的json或yaml
+func (g *generic) UpdateResourceHandler(ctx *gin.Context) {
+	params := new(struct {
+		resourceParams
+		Namespace string `json:"namespace"`
+		Content   string `json:"content"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := g.resolve(ctx, &params.resourceParams)
+	if !ok {
+		return
+	}
+	if err := service.Generic.UpdateResource(cc, mapping, params.Namespace, params.Content); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 更新%s成功", mapping.Kind),
+		"data": nil,
+	})
+}
+
+// DeleteResourceHandler 删除任意资源
+func (g *generic) DeleteResourceHandler(ctx *gin.Context) {
+	params := new(struct {
+		resourceParams
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, mapping, ok := g.resolve(ctx, &params.resourceParams)
+	if !ok {
+		return
+	}
+	if err := service.Generic.DeleteResource(cc, mapping, params.Namespace, params.Name); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 删除%s成功", mapping.Kind),
+		"data": nil,
+	})
+}
+
+// resolve 获取集群客户端并解析资源，集群不存在时返回400，不可达时返回503，不支持该资源时返回404，调用方直接返回即可
+func (g *generic) resolve(ctx *gin.Context, params *resourceParams) (*service.ClusterClient, *service.ResourceMapping, bool) {
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return nil, nil, false
+	}
+	mapping, err := service.Generic.Resolve(cc, params.Group, params.Version, params.Resource)
+	if err != nil {
+		serviceError(ctx, err)
+		return nil, nil, false
+	}
+	return cc, mapping, true
+}
\ No newline at end of file
@@ -17,11 +17,9 @@ func (i *ingress) GetIngressHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace string `form:"namespace"`
+		Cluster   string `form:"cluster"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -33,7 +31,7 @@ func (i *ingress) GetIngressHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -42,19 +40,20 @@ func (i *ingress) GetIngressHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.Ingress.GetIngress(client, params.FilterName, params.Namespace, params.Limit, params.Page)
+	cc, err := service.K8s.GetClusterClientFor(params.Cluster, service.IngressGVR)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Ingress.GetIngress(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Ingress列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -63,6 +62,7 @@ func (i *ingress) GetIngressDetailHandler(ctx *gin.Context) {
 	//    处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		IngressName string `form:"ingress_name"`
 		Namespace   string `form:"namespace"`
 		Cluster     string `form:"cluster"`
@@ -77,28 +77,25 @@ func (i *ingress) GetIngressDetailHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClientFor(params.Cluster, service.IngressGVR)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
-	data, err := service.Ingress.GetIngressDetail(client, params.IngressName, params.Namespace)
+	data, err := service.Ingress.GetIngressDetail(cc.ClientSet, params.IngressName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Ingress详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -118,22 +115,14 @@ func (i *ingress) CreateIngressHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(ingressCreate.Cluster)
+	cc, err := service.K8s.GetClusterClientFor(ingressCreate.Cluster, service.IngressGVR)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行创建
-	if err = service.Ingress.CreateIngress(client, ingressCreate); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+	if err = service.Ingress.CreateIngress(cc.ClientSet, ingressCreate); err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -160,22 +149,14 @@ func (i *ingress) DeleteIngressHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClientFor(params.Cluster, service.IngressGVR)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行删除
-	if err := service.Ingress.DeleteIngress(client, params.IngressName, params.Namespace); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+	if err := service.Ingress.DeleteIngress(cc.ClientSet, params.IngressName, params.Namespace); err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -202,22 +183,14 @@ func (i *ingress) UpdateIngressHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClientFor(params.Cluster, service.IngressGVR)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
-	err = service.Ingress.UpdateIngress(client, params.Namespace, params.Content)
+	err = service.Ingress.UpdateIngress(cc.ClientSet, params.Namespace, params.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
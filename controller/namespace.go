@@ -17,10 +17,8 @@ func (n *namespace) GetNamespacesHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Cluster string `form:"cluster"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -32,7 +30,7 @@ func (n *namespace) GetNamespacesHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -41,19 +39,20 @@ func (n *namespace) GetNamespacesHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.Namespace.GetNamespaces(client, params.FilterName, params.Limit, params.Page)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Namespace.GetNamespaces(cc, query)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Namespace列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -61,6 +60,7 @@ func (n *namespace) GetNamespacesHandler(ctx *gin.Context) {
 func (n *namespace) GetNamespaceDetailHandler(ctx *gin.Context) {
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		NamespaceName string `form:"namespace_name"`
 		Cluster       string `form:"cluster"`
 	})
@@ -76,26 +76,23 @@ func (n *namespace) GetNamespaceDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.Namespace.GetNamespaceDetail(client, params.NamespaceName)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Namespace详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -117,20 +114,12 @@ func (n *namespace) CreateNamespaceHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(namespaceCreate.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行创建
 	if err = service.Namespace.CreateNamespace(client, namespaceCreate); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -157,20 +146,12 @@ func (n *namespace) DeleteNamespaceHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.Namespace.DeleteNamespace(client, params.NamespaceName)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
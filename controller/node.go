@@ -17,10 +17,8 @@ func (n *node) GetNodesHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Cluster string `form:"cluster"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -32,7 +30,7 @@ func (n *node) GetNodesHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -41,19 +39,20 @@ func (n *node) GetNodesHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.Node.GetNodes(client, params.FilterName, params.Limit, params.Page)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Node.GetNodes(cc, query)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Node列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -61,6 +60,7 @@ func (n *node) GetNodesHandler(ctx *gin.Context) {
 func (n *node) GetNodeDetailHandler(ctx *gin.Context) {
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		NodeName string `form:"node_name"`
 		Cluster  string `form:"cluster"`
 	})
@@ -76,25 +76,51 @@ func (n *node) GetNodeDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.Node.GetNodeDetail(client, params.NodeName)
 	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取Node详情成功",
+		"data": content,
+	})
+}
+
+// GetNodeHistoriesHandler 获取node变更记录，host_name为空时返回集群所有node的记录
+func (n *node) GetNodeHistoriesHandler(ctx *gin.Context) {
+	params := new(struct {
+		Cluster  string `form:"cluster"`
+		HostName string `form:"host_name"`
+		Page     int    `form:"page"`
+		Limit    int    `form:"limit"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	data, err := service.Node.GetNodeHistories(params.Cluster, params.HostName, params.Page, params.Limit)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 获取Node详情成功",
+		"msg":  "success, 获取Node变更记录成功",
 		"data": data,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"k8sManagerApi/service"
+)
+
+// listParams 列表接口共用的过滤、排序、分页参数，嵌入到各接口的参数结构体中使用
+// label_selector、field_selector的格式与kubectl一致，sort_by格式为 字段[:asc|desc]，多个字段用逗号分隔
+// paginate_mode为continue时使用apiserver分页，下一页传入上次返回的continue，此时不支持排序
+// view为summary时只返回计算后的关键字段，不返回完整的资源对象
+type listParams struct {
+	FilterName    string `form:"filter_name"`
+	LabelSelector string `form:"label_selector"`
+	FieldSelector string `form:"field_selector"`
+	Status        string `form:"status"`
+	SortBy        string `form:"sort_by"`
+	Limit         int    `form:"limit"`
+	Page          int    `form:"page"`
+	PaginateMode  string `form:"paginate_mode"`
+	Continue      string `form:"continue"`
+	View          string `form:"view"`
+}
+
+// summarizer 支持精简视图的列表返回内容
+type summarizer interface {
+	Summary() *service.SummaryResp
+}
+
+// query 转换成service层的查询条件，选择器或排序字段格式错误时返回错误
+func (l *listParams) query() (*service.DataSelectQuery, error) {
+	if err := service.CheckView(l.View); err != nil {
+		return nil, err
+	}
+	filter, err := service.NewFilterQuery(l.FilterName, l.LabelSelector, l.FieldSelector, l.Status)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := service.NewSortQuery(l.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	paginate, err := service.NewPaginateQuery(l.PaginateMode, l.Limit, l.Page, l.Continue)
+	if err != nil {
+		return nil, err
+	}
+	// apiserver按名称顺序返回数据，分页后无法再整体排序
+	if paginate.Mode == service.PaginateModeContinue && len(sort.Fields) > 0 {
+		return nil, errors.New("continue分页不支持sort_by排序")
+	}
+	return &service.DataSelectQuery{
+		Filter:   filter,
+		Sort:     sort,
+		Paginate: paginate,
+	}, nil
+}
+
+// view 按view参数返回完整列表或精简视图
+func (l *listParams) view(data summarizer) interface{} {
+	if l.View == service.ViewSummary {
+		return data.Summary()
+	}
+	return data
+}
+
+// detailParams 详情接口共用的参数，format为yaml时返回去掉status、managedFields的yaml，编辑后可直接提交给更新接口
+type detailParams struct {
+	Format string `form:"format" binding:"omitempty,oneof=json yaml"`
+}
+
+// render 按format返回资源对象或yaml
+func (d *detailParams) render(obj interface{}) (interface{}, error) {
+	return service.RenderDetail(d.Format, obj)
+}
\ No newline at end of file
@@ -6,6 +6,7 @@ import (
 	"go.uber.org/zap"
 	"k8sManagerApi/service"
 	"net/http"
+	"time"
 )
 
 var Pod pod
@@ -20,11 +21,10 @@ func (p *pod) GetPodsHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace   string `form:"namespace"`
+		Cluster     string `form:"cluster"`
+		AllClusters bool   `form:"all_clusters"`
 	})
 
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
@@ -37,7 +37,7 @@ func (p *pod) GetPodsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -46,19 +46,33 @@ func (p *pod) GetPodsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.Pod.GetPods(client, params.FilterName, params.Namespace, params.Limit, params.Page)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
+	if params.AllClusters {
+		data, err := service.Pod.GetPodsAllClusters(params.Namespace, query)
+		if err != nil {
+			serviceError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"code": http.StatusOK,
+			"msg":  "success, 跨集群获取Pod列表成功",
+			"data": params.view(data),
 		})
 		return
 	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Pod.GetPods(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Pod列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -67,6 +81,7 @@ func (p *pod) GetPodDetailHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		PodName   string `form:"pod_name"`
 		Namespace string `form:"namespace"`
 		Cluster   string `form:"cluster"`
@@ -83,26 +98,23 @@ func (p *pod) GetPodDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.Pod.GetPodDetail(client, params.PodName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取Pod详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -127,20 +139,12 @@ func (p *pod) DeletePodHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.Pod.DeletePod(client, params.PodName, params.Namespace, params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -171,20 +175,12 @@ func (p *pod) UpdatePodHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.Pod.UpdatePod(client, params.Namespace, params.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -215,21 +211,13 @@ func (p *pod) GetPodContainerHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 
 	data, err := service.Pod.GetPodContainer(client, params.PodName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -261,20 +249,12 @@ func (p *pod) GetPodLogHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.Pod.GetPodLog(client, params.ContainerName, params.PodName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -298,35 +278,90 @@ func (p *pod) GetPodNumberHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Pod.GetPodNumPerNp(cc)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取每个namespace的pod数量成功",
+		"data": data,
+	})
+}
+
+// GetAllPodsInfoHandler 获取集群的所有Pod信息进行入库操作
+// Deprecated: Pod信息已由informer自动入库，保留该接口兼容旧的调用方，不再做任何操作，查询请使用/api/k8s/pod/history
+func (p *pod) GetAllPodsInfoHandler(ctx *gin.Context) {
+	params := new(struct {
+		Cluster string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
-	data, err := service.Pod.GetPodNumPerNp(client)
-	if err != nil {
+	if _, err := service.K8s.GetClient(params.Cluster); err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	ctx.Header("Deprecation", "true")
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取所有的Pod信息成功",
+		"data": nil,
+	})
+}
+
+// GetPodHistoriesHandler 获取Pod生命周期记录，支持按工作负载、节点及时间段查询，时间格式 2006-01-02 15:04:05
+func (p *pod) GetPodHistoriesHandler(ctx *gin.Context) {
+	params := new(struct {
+		Cluster      string    `form:"cluster"`
+		Namespace    string    `form:"namespace"`
+		WorkloadKind string    `form:"workload_kind"`
+		Workload     string    `form:"workload"`
+		NodeName     string    `form:"node_name"`
+		StartTime    time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+		EndTime      time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+		Page         int       `form:"page"`
+		Limit        int       `form:"limit"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	data, err := service.Pod.GetPodHistories(params.Cluster, params.Namespace, params.WorkloadKind, params.Workload, params.NodeName,
+		params.StartTime, params.EndTime, params.Page, params.Limit)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 获取每个namespace的pod数量成功",
+		"msg":  "success, 获取Pod生命周期记录成功",
 		"data": data,
 	})
 }
 
-// GetAllPodsInfoHandler 获取集群的所有Pod信息进行入库操作及可视化渲染使用
-func (p *pod) GetAllPodsInfoHandler(ctx *gin.Context) {
+// GetPodPhasesHandler 获取单个Pod的状态变化记录
+func (p *pod) GetPodPhasesHandler(ctx *gin.Context) {
 	params := new(struct {
 		Cluster string `form:"cluster"`
+		UID     string `form:"uid"`
 	})
 	if err := ctx.Bind(params); err != nil {
 		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
@@ -337,20 +372,14 @@ func (p *pod) GetAllPodsInfoHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	data, err := service.Pod.GetPodPhases(params.Cluster, params.UID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
-	service.Pod.GetAllPodsInfo(client, params.Cluster)
-
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 获取所有的Pod信息成功",
-		"data": nil,
+		"msg":  "success, 获取Pod状态变化记录成功",
+		"data": data,
 	})
 }
\ No newline at end of file
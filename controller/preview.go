@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"k8sManagerApi/service"
+	"net/http"
+)
+
+var Preview preview
+
+type preview struct{}
+
+// PreviewHandler 更新前预览，以dry run方式提交content，返回字段差异、unified diff及不可变字段、滚动更新的提示
+func (p *preview) PreviewHandler(ctx *gin.Context) {
+	params := new(struct {
+		Cluster   string `json:"cluster"`
+		Namespace string `json:"namespace"` // content中未指定namespace的资源使用
+		Content   string `json:"content"`   // 与更新接口相同的json或yaml
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Preview.Preview(cc, params.Namespace, params.Content)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 预览更新成功",
+		"data": data,
+	})
+}
\ No newline at end of file
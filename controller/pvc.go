@@ -16,11 +16,9 @@ type pvc struct{}
 func (p *pvc) GetPvcsHandler(ctx *gin.Context) {
 	// 处理请求参数
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace string `form:"namespace"`
+		Cluster   string `form:"cluster"`
 	})
 	if err := ctx.Bind(params); err != nil {
 		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
@@ -31,7 +29,7 @@ func (p *pvc) GetPvcsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -40,25 +38,27 @@ func (p *pvc) GetPvcsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.Pvc.GetPvcs(client, params.Namespace, params.FilterName, params.Limit, params.Page)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.Pvc.GetPvcs(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取PVC列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
 // GetPvcDetailHandler 获取PVC详情
 func (p *pvc) GetPvcDetailHandler(ctx *gin.Context) {
 	params := new(struct {
+		detailParams
 		Namespace string `form:"namespace"`
 		PvcName   string `form:"pvc_name"`
 		Cluster   string `form:"cluster"`
@@ -74,26 +74,23 @@ func (p *pvc) GetPvcDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.Pvc.GetPvcDetail(client, params.Namespace, params.PvcName)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取PVC详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -115,20 +112,12 @@ func (p *pvc) UpdatePvcHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.Pvc.UpdatePvc(client, params.Namespace, params.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -156,20 +145,12 @@ func (p *pvc) DeletePvcHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.Pvc.DeletePvc(client, params.Namespace, params.PvcName)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"k8sManagerApi/service"
+	"net/http"
+	"time"
+)
+
+var Rollout rollout
+
+type rollout struct{}
+
+// WatchRolloutHandler 以SSE推送Deployment、StatefulSet、DaemonSet的滚动更新进度，done事件后结束
+func (r *rollout) WatchRolloutHandler(ctx *gin.Context) {
+	params := new(struct {
+		Kind      string `form:"kind"` // Deployment、StatefulSet、DaemonSet
+		Name      string `form:"name"`
+		Namespace string `form:"namespace"`
+		Timeout   int    `form:"timeout"` // 秒，为0时默认10分钟，最长1小时
+		Cluster   string `form:"cluster"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	// 请求断开时ctx.Request.Context()取消，监听协程随之退出
+	events, err := service.Rollout.Watch(ctx.Request.Context(), cc, params.Kind, params.Namespace, params.Name, time.Duration(params.Timeout)*time.Second)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	// 关闭nginx等反向代理的缓冲，保证事件及时推送
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Stream(func(w io.Writer) bool {
+		event, ok := <-events
+		if !ok {
+			return false
+		}
+		ctx.SSEvent(event.Type, event)
+		return true
+	})
+}
\ No newline at end of file
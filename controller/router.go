@@ -38,8 +38,12 @@ func (r *router) InitApiRouter(router *gin.Engine) {
 	router.PUT("/api/k8s/pod/update", Pod.UpdatePodHandler)
 	// 删除Pod的路由，DELETE请求，路径为"/api/k8s/pod/del"，处理函数为Pod.DeletePodHandler
 	router.DELETE("/api/k8s/pod/del", Pod.DeletePodHandler)
-	// 获取集群的所有Pod信息的路由
+	// 获取集群的所有Pod信息的路由，已废弃，Pod信息由informer自动入库
 	router.GET("/api/k8s/pod/all", Pod.GetAllPodsInfoHandler)
+	// 获取Pod生命周期记录的路由，支持按工作负载、节点及时间段查询
+	router.GET("/api/k8s/pod/history", Pod.GetPodHistoriesHandler)
+	// 获取单个Pod状态变化记录的路由
+	router.GET("/api/k8s/pod/phases", Pod.GetPodPhasesHandler)
 
 	// 以下为Deployment相关的路由和处理函数
 	// 获取所有Deployments的路由，GET请求，路径为"/api/k8s/deployments"，处理函数为Deployment.GetDeploymentsHandler
@@ -58,6 +62,17 @@ func (r *router) InitApiRouter(router *gin.Engine) {
 	router.POST("/api/k8s/deployment/create", Deployment.CreateDeploymentHandler)
 	// 删除Deployment的路由，DELETE请求，路径为"/api/k8s/deployment/del"，处理函数为Deployment.DeleteDeploymentHandler
 	router.DELETE("/api/k8s/deployment/del", Deployment.DeleteDeploymentHandler)
+	// Deployment历史版本、版本对比及回滚，版本来自Deployment拥有的ReplicaSet
+	router.GET("/api/k8s/deployment/revisions", Deployment.GetDeploymentRevisionsHandler)
+	router.GET("/api/k8s/deployment/revision/diff", Deployment.GetDeploymentRevisionDiffHandler)
+	router.PUT("/api/k8s/deployment/rollback", Deployment.RollbackDeploymentHandler)
+	// 滚动更新进度，SSE推送Deployment、StatefulSet、DaemonSet的更新状态直到完成、失败或超时
+	router.GET("/api/k8s/rollout/watch", Rollout.WatchRolloutHandler)
+	// 工作负载的定向操作，只patch相关字段，kind为Deployment、StatefulSet、DaemonSet
+	router.PUT("/api/k8s/workload/image", Workload.SetImageHandler)
+	router.PUT("/api/k8s/workload/change_cause", Workload.SetChangeCauseHandler)
+	router.PUT("/api/k8s/workload/pause", Workload.PauseHandler)
+	router.PUT("/api/k8s/workload/resume", Workload.ResumeHandler)
 
 	// 以下为DaemonSet相关的路由和处理函数
 	router.GET("/api/k8s/daemonSets", DaemonSet.GetDaemonSetsHandler)
@@ -66,6 +81,7 @@ func (r *router) InitApiRouter(router *gin.Engine) {
 	router.DELETE("/api/k8s/daemonSet/del", DaemonSet.DeleteDaemonSetHandler)
 	router.PUT("/api/k8s/daemonSet/update", DaemonSet.UpdateDaemonSetHandler)
 	router.POST("/api/k8s/daemonSet/create", DaemonSet.CreateDaemonSetHandler)
+	router.PUT("/api/k8s/daemonSet/restart", DaemonSet.RestartDaemonSetHandler)
 
 	// 以下为StatefulSet相关的路由和处理函数
 	router.GET("/api/k8s/statefulSets", StatefulSet.GetStatefulSetsHandler)
@@ -73,10 +89,17 @@ func (r *router) InitApiRouter(router *gin.Engine) {
 	router.GET("/api/k8s/statefulSet/numnp", StatefulSet.GetStatefulSetNumPerNpHandler)
 	router.DELETE("/api/k8s/statefulSet/del", StatefulSet.DeleteStatefulSetHandler)
 	router.PUT("/api/k8s/statefulSet/update", StatefulSet.UpdateStatefulSetHandler)
+	router.PUT("/api/k8s/statefulSet/restart", StatefulSet.RestartStatefulSetHandler)
+	router.POST("/api/k8s/statefulSet/create", StatefulSet.CreateStatefulSetHandler)
+	router.PUT("/api/k8s/statefulSet/scale", StatefulSet.SetStatefulSetReplicasHandler)
+	// 修改partition，逐步调小即可按序号分批发布
+	router.PUT("/api/k8s/statefulSet/partition", StatefulSet.SetStatefulSetPartitionHandler)
 
 	// 以下是Node相关的路由和处理函数
 	router.GET("/api/k8s/nodes", Node.GetNodesHandler)
 	router.GET("/api/k8s/node/detail", Node.GetNodeDetailHandler)
+	// 获取node变更记录，如kubelet升级、内核变更、容量变化及节点上下线
+	router.GET("/api/k8s/node/history", Node.GetNodeHistoriesHandler)
 
 	// 以下是Namespace相关的路由和处理函数
 	router.GET("/api/k8s/namespaces", Namespace.GetNamespacesHandler)
@@ -126,6 +149,25 @@ func (r *router) InitApiRouter(router *gin.Engine) {
 	router.PUT("/api/k8s/pvc/update", Pvc.UpdatePvcHandler)
 	router.DELETE("/api/k8s/pvc/del", Pvc.DeletePvcHandler)
 
+	// 以下是通用资源相关的路由和处理函数，通过group、version、resource指定任意资源
+	router.GET("/api/k8s/resources", Generic.GetResourcesHandler)
+	router.GET("/api/k8s/resource/detail", Generic.GetResourceDetailHandler)
+	router.POST("/api/k8s/resource/create", Generic.CreateResourceHandler)
+	router.PUT("/api/k8s/resource/update", Generic.UpdateResourceHandler)
+	router.DELETE("/api/k8s/resource/del", Generic.DeleteResourceHandler)
+	// 使用server-side apply应用多文档manifest，资源类型由内容中的apiVersion、kind决定
+	router.POST("/api/k8s/apply", Apply.ApplyHandler)
+	// 更新前预览，dry run提交后返回与当前资源的差异，不修改集群中的资源
+	router.POST("/api/k8s/preview", Preview.PreviewHandler)
+
+	// 以下是CRD及自定义资源相关的路由和处理函数
+	router.GET("/api/k8s/crds", CRD.GetCRDsHandler)
+	router.GET("/api/k8s/crd/detail", CRD.GetCRDDetailHandler)
+	router.GET("/api/k8s/crd/resources", CRD.GetCustomResourcesHandler)
+	router.GET("/api/k8s/crd/resource/detail", CRD.GetCustomResourceDetailHandler)
+	router.PUT("/api/k8s/crd/resource/update", CRD.UpdateCustomResourceHandler)
+	router.DELETE("/api/k8s/crd/resource/del", CRD.DeleteCustomResourceHandler)
+
 	// 以下是登录注册相关的路由和处理函数
 	router.POST("/api/login", Auth.LoginHandler)
 	// 注册路由
@@ -135,6 +177,19 @@ func (r *router) InitApiRouter(router *gin.Engine) {
 
 	// 获取集群列表
 	router.GET("/api/k8s/clusters", Cluster.GetClustersHandler)
+	// 获取集群健康状态
+	router.GET("/api/k8s/cluster/status", Cluster.GetClusterStatusHandler)
+	// 获取集群informer缓存的同步状态
+	router.GET("/api/k8s/cluster/cache", Cluster.GetClusterCacheHandler)
+	// 获取集群版本、API组及CRD，用于判断集群支持的资源
+	router.GET("/api/k8s/cluster/capabilities", Cluster.GetClusterCapabilitiesHandler)
+	// 注册、更新、删除集群，上传kubeconfig后无需修改配置文件及重启服务
+	router.POST("/api/k8s/cluster/create", Cluster.CreateClusterHandler)
+	router.PUT("/api/k8s/cluster/update", Cluster.UpdateClusterHandler)
+	router.DELETE("/api/k8s/cluster/del", Cluster.DeleteClusterHandler)
+
+	// 全局搜索，跨集群、namespace及资源类型
+	router.GET("/api/k8s/search", Search.SearchHandler)
 
 	// 获取集群所有资源
 	router.GET("/api/k8s/allres", AllRes.GetAllNumHandler)
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"k8sManagerApi/service"
+	"net/http"
+	"strings"
+)
+
+var Search search
+
+type search struct{}
+
+// SearchHandler 在所有集群中搜索资源，匹配名称、镜像、ingress域名、label及annotation
+func (s *search) SearchHandler(ctx *gin.Context) {
+	params := new(struct {
+		listParams
+		Keyword   string `form:"q"`
+		Kinds     string `form:"kinds"` // 逗号分隔，如Pod,Deployment，为空时搜索所有资源
+		Namespace string `form:"namespace"`
+	})
+	if err := ctx.Bind(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	query, err := params.query()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	var kinds []string
+	for _, kind := range strings.Split(params.Kinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	data, err := service.Search.Search(params.Keyword, params.Namespace, kinds, query)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 搜索成功",
+		"data": params.view(data),
+	})
+}
\ No newline at end of file
@@ -17,11 +17,10 @@ func (s *statefulSet) GetStatefulSetsHandler(ctx *gin.Context) {
 	//	处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
-		FilterName string `form:"filter_name"`
-		Namespace  string `form:"namespace"`
-		Limit      int    `form:"limit"`
-		Page       int    `form:"page"`
-		Cluster    string `form:"cluster"`
+		listParams
+		Namespace   string `form:"namespace"`
+		Cluster     string `form:"cluster"`
+		AllClusters bool   `form:"all_clusters"`
 	})
 	// form格式使用Bind方法，json格式使用SholdBindJson方法
 	if err := ctx.Bind(params); err != nil {
@@ -33,7 +32,7 @@ func (s *statefulSet) GetStatefulSetsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	query, err := params.query()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
@@ -42,19 +41,33 @@ func (s *statefulSet) GetStatefulSetsHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	data, err := service.StatefulSet.GetStatefulSets(client, params.FilterName, params.Namespace, params.Limit, params.Page)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
+	if params.AllClusters {
+		data, err := service.StatefulSet.GetStatefulSetsAllClusters(params.Namespace, query)
+		if err != nil {
+			serviceError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"code": http.StatusOK,
+			"msg":  "success, 跨集群获取StatefulSet列表成功",
+			"data": params.view(data),
 		})
 		return
 	}
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.StatefulSet.GetStatefulSets(cc, params.Namespace, query)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取StatefulSet列表成功",
-		"data": data,
+		"data": params.view(data),
 	})
 }
 
@@ -63,6 +76,7 @@ func (s *statefulSet) GetStatefulSetDetailHandler(ctx *gin.Context) {
 	//    处理请求参数
 	// 匿名结构体，用于定义传入参数，get请求为form格式，其它请求为json格式
 	params := new(struct {
+		detailParams
 		StatefulSetName string `form:"statefulset_name"`
 		Namespace       string `form:"namespace"`
 		Cluster         string `form:"cluster"`
@@ -79,26 +93,23 @@ func (s *statefulSet) GetStatefulSetDetailHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	data, err := service.StatefulSet.GetStatefulSetDetail(client, params.StatefulSetName, params.Namespace)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
+		return
+	}
+	content, err := params.render(data)
+	if err != nil {
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"msg":  "success, 获取StatefulSet详情成功",
-		"data": data,
+		"data": content,
 	})
 }
 
@@ -121,25 +132,51 @@ func (s *statefulSet) DeleteStatefulSetHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	// 调用Service层方法进行删除
 	if err := service.StatefulSet.DeleteStatefulSet(client, params.StatefulSetName, params.Namespace); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 删除StatefulSet成功",
+		"data": nil,
+	})
+}
+
+// RestartStatefulSetHandler 重启StatefulSet
+func (s *statefulSet) RestartStatefulSetHandler(ctx *gin.Context) {
+	params := new(struct {
+		StatefulSetName string `json:"statefulset_name"`
+		Namespace       string `json:"namespace"`
+		Cluster         string `json:"cluster"`
+	})
+	// form格式使用Bind方法，json格式使用SholdBindJson方法
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	// 调用Service层方法进行重启
+	if err := service.StatefulSet.RestartStatefulSet(client, params.StatefulSetName, params.Namespace); err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 删除StatefulSet成功",
+		"msg":  "success, 重启StatefulSet成功",
 		"data": nil,
 	})
 }
@@ -163,20 +200,12 @@ func (s *statefulSet) UpdateStatefulSetHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	err = service.StatefulSet.UpdateStatefulSet(client, params.Namespace, params.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		serviceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -200,27 +229,119 @@ func (s *statefulSet) GetStatefulSetNumPerNpHandler(ctx *gin.Context) {
 		})
 		return
 	}
-	client, err := service.K8s.GetClient(params.Cluster)
+	cc, err := service.K8s.GetClusterClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
+		clusterError(ctx, err)
+		return
+	}
+	data, err := service.StatefulSet.GetStatefulSetNumPerNp(cc)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 获取每个namespace的StatefulSet数量成功",
+		"data": data,
+	})
+}
+
+// CreateStatefulSetHandler 创建StatefulSet，headless Service不存在时自动创建
+func (s *statefulSet) CreateStatefulSetHandler(ctx *gin.Context) {
+	var (
+		statefulSetCreate = new(service.StatefulSetCreate)
+		err               error
+	)
+	if err = ctx.ShouldBindJSON(statefulSetCreate); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
-	data, err := service.StatefulSet.GetStatefulSetNumPerNp(client)
+	client, err := service.K8s.GetClient(statefulSetCreate.Cluster)
 	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	// 调用Service层方法进行创建
+	if err = service.StatefulSet.CreateStatefulSet(client, statefulSetCreate); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 创建StatefulSet成功",
+		"data": nil,
+	})
+}
+
+// SetStatefulSetReplicasHandler 设置StatefulSet副本数
+func (s *statefulSet) SetStatefulSetReplicasHandler(ctx *gin.Context) {
+	params := new(struct {
+		Namespace       string `json:"namespace"`
+		StatefulSetName string `json:"statefulset_name"`
+		Replicas        int32  `json:"replicas"`
+		Cluster         string `json:"cluster"`
+	})
+	// form格式使用Bind方法，json格式使用SholdBindJson方法
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
-			"msg":  err.Error(),
+			"msg":  "Bind绑定参数失败" + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	replicas, err := service.StatefulSet.SetStatefulSetReplicas(client, params.StatefulSetName, params.Namespace, params.Replicas)
+	if err != nil {
+		serviceError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "success, 获取每个namespace的StatefulSet数量成功",
-		"data": data,
+		"msg":  "success, 设置StatefulSet副本数成功",
+		"data": replicas,
+	})
+}
+
+// SetStatefulSetPartitionHandler 修改StatefulSet滚动更新的partition，用于分批发布
+func (s *statefulSet) SetStatefulSetPartitionHandler(ctx *gin.Context) {
+	params := new(struct {
+		Namespace       string `json:"namespace"`
+		StatefulSetName string `json:"statefulset_name"`
+		Partition       int32  `json:"partition"`
+		Cluster         string `json:"cluster"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	if err := service.StatefulSet.SetStatefulSetPartition(client, params.StatefulSetName, params.Namespace, params.Partition); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "success, 修改StatefulSet partition成功",
+		"data": params.Partition,
 	})
 }
\ No newline at end of file
@@ -97,11 +97,7 @@ func (w *workflow) CreateWorkflowHandler(ctx *gin.Context) {
 	fmt.Println("controller", 999)
 	client, err := service.K8s.GetClient(wc.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	if err = service.Workflow.CreateWorkflow(client, wc); err != nil {
@@ -137,11 +133,7 @@ func (w *workflow) DeleteWorkflowHandler(ctx *gin.Context) {
 	}
 	client, err := service.K8s.GetClient(params.Cluster)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code": http.StatusBadRequest,
-			"msg":  err.Error(),
-			"data": nil,
-		})
+		clusterError(ctx, err)
 		return
 	}
 	if err := service.Workflow.DeleteWorkflow(client, params.ID); err != nil {
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"k8sManagerApi/service"
+	"net/http"
+)
+
+var Workload workload
+
+type workload struct{}
+
+// workloadParams 工作负载操作共用的参数，kind为Deployment、StatefulSet、DaemonSet
+type workloadParams struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+}
+
+// SetImageHandler 修改工作负载中指定容器的镜像
+func (w *workload) SetImageHandler(ctx *gin.Context) {
+	params := new(struct {
+		workloadParams
+		Container   string `json:"container"`
+		Image       string `json:"image"`
+		ChangeCause string `json:"change_cause"` // 为空时记录为set image container=image
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	if err := service.Workload.SetImage(client, params.Kind, params.Namespace, params.Name, params.Container, params.Image, params.ChangeCause); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 修改%s镜像成功", params.Kind),
+		"data": nil,
+	})
+}
+
+// SetChangeCauseHandler 记录工作负载的change-cause
+func (w *workload) SetChangeCauseHandler(ctx *gin.Context) {
+	params := new(struct {
+		workloadParams
+		ChangeCause string `json:"change_cause"`
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	if err := service.Workload.SetChangeCause(client, params.Kind, params.Namespace, params.Name, params.ChangeCause); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 记录%s change-cause成功", params.Kind),
+		"data": nil,
+	})
+}
+
+// PauseHandler 暂停滚动更新，支持Deployment、StatefulSet、DaemonSet
+func (w *workload) PauseHandler(ctx *gin.Context) {
+	params := new(struct {
+		workloadParams
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	if err := service.Workload.Pause(client, params.Kind, params.Namespace, params.Name); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 暂停%s滚动更新成功", params.Kind),
+		"data": nil,
+	})
+}
+
+// ResumeHandler 恢复滚动更新，还原暂停前的partition或更新策略
+func (w *workload) ResumeHandler(ctx *gin.Context) {
+	params := new(struct {
+		workloadParams
+	})
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		zap.L().Error(fmt.Sprintf("Bind绑定参数失败, %v", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "Bind绑定参数失败" + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	client, err := service.K8s.GetClient(params.Cluster)
+	if err != nil {
+		clusterError(ctx, err)
+		return
+	}
+	if err := service.Workload.Resume(client, params.Kind, params.Namespace, params.Name); err != nil {
+		serviceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  fmt.Sprintf("success, 恢复%s滚动更新成功", params.Kind),
+		"data": nil,
+	})
+}
\ No newline at end of file
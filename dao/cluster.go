@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"k8sManagerApi/db/mysql"
+	"k8sManagerApi/model"
+)
+
+var Cluster cluster
+
+type cluster struct{}
+
+// GetAll 获取所有通过接口注册的集群，用于服务启动时加载
+func (c *cluster) GetAll() (clusters []*model.Cluster, err error) {
+	clusters = make([]*model.Cluster, 0)
+	tx := mysql.DB.Model(&model.Cluster{}).Order("id asc").Find(&clusters)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("获取集群列表失败, %v", tx.Error))
+		return nil, errors.New(fmt.Sprintf("获取集群列表失败, %v", tx.Error))
+	}
+	return clusters, nil
+}
+
+// HasCluster 根据集群名查询单个集群
+func (c *cluster) HasCluster(name string) (*model.Cluster, bool, error) {
+	data := &model.Cluster{}
+	tx := mysql.DB.Where("name = ?", name).First(&data)
+	if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("查询集群失败, %v", tx.Error))
+		return nil, false, errors.New(fmt.Sprintf("查询集群失败, %v", tx.Error))
+	}
+	return data, true, nil
+}
+
+// Add 新增集群
+func (c *cluster) Add(cluster *model.Cluster) (err error) {
+	tx := mysql.DB.Create(&cluster)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("新增集群失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("新增集群失败, %v", tx.Error))
+	}
+	return nil
+}
+
+// Update 更新集群的kubeconfig及版本信息
+func (c *cluster) Update(cluster *model.Cluster) (err error) {
+	tx := mysql.DB.Model(&cluster).Updates(&model.Cluster{
+		KubeConfig: cluster.KubeConfig,
+		Version:    cluster.Version,
+		Server:     cluster.Server,
+		Describe:   cluster.Describe,
+	})
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("更新集群失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("更新集群失败, %v", tx.Error))
+	}
+	return nil
+}
+
+// DeleteByName 根据集群名删除集群
+func (c *cluster) DeleteByName(name string) (err error) {
+	tx := mysql.DB.Where("name = ?", name).Delete(&model.Cluster{})
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("删除集群失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("删除集群失败, %v", tx.Error))
+	}
+	return nil
+}
\ No newline at end of file
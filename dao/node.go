@@ -63,26 +63,70 @@ func (n *node) GetNodeList(cluster string) (nodeList []*ClusterNodes, err error)
 	return nodeList, nil
 }
 
-// Add 新增node，当获取集群的node时候进行入库
-func (n *node) Add(node *model.Node) (err error) {
-	// 先判断节点是否存在，如果存在则不添加
-	existingNode := &model.Node{}
-	result := mysql.DB.Where("host_name = ? and cluster = ? and ip = ?", node.HostName, node.Cluster, node.IP).First(&existingNode)
-	if result == nil {
-		// 节点已存在，不进行插入操作
-		zap.L().Warn(fmt.Sprintf("节点已存在, cluster: %v, ip: %v", node.Cluster, node.IP))
-		return nil
-	} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		// 查询出错
-		zap.L().Error(fmt.Sprintf("查询节点失败: %v", result.Error))
-		return errors.New(fmt.Sprintf("查询节点失败: %v", result.Error))
+// HasNode 根据集群及主机名查询node，包括已被软删除的记录，节点重新加入集群时复用原记录
+func (n *node) HasNode(cluster, hostName string) (*model.Node, bool, error) {
+	data := &model.Node{}
+	tx := mysql.DB.Unscoped().Where("cluster = ? and host_name = ?", cluster, hostName).First(&data)
+	if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("查询node失败, %v", tx.Error))
+		return nil, false, errors.New(fmt.Sprintf("查询node失败, %v", tx.Error))
+	}
+	return data, true, nil
+}
+
+// GetHostNames 获取集群中未删除的node主机名，用于清理已下线的节点
+func (n *node) GetHostNames(cluster string) (hostNames []string, err error) {
+	tx := mysql.DB.Model(&model.Node{}).Where("cluster = ?", cluster).Pluck("host_name", &hostNames)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("获取node列表失败, %v", tx.Error))
+		return nil, errors.New(fmt.Sprintf("获取node列表失败, %v", tx.Error))
 	}
+	return hostNames, nil
+}
 
-	// 插入新节点
+// RemoveDuplicates 清理集群中主机名重复的历史记录，每个主机名只保留id最大的一条，包括已被软删除的记录
+func (n *node) RemoveDuplicates(cluster string) (err error) {
+	tx := mysql.DB.Exec("DELETE n1 FROM node n1 JOIN node n2 ON n1.cluster = n2.cluster AND n1.host_name = n2.host_name AND n1.id < n2.id WHERE n1.cluster = ?", cluster)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("清理重复node失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("清理重复node失败, %v", tx.Error))
+	}
+	if tx.RowsAffected > 0 {
+		zap.L().Info(fmt.Sprintf("清理重复node %d条", tx.RowsAffected), zap.String("cluster", cluster))
+	}
+	return nil
+}
+
+// Add 新增node
+func (n *node) Add(node *model.Node) (err error) {
 	tx := mysql.DB.Create(&node)
 	if tx.Error != nil {
 		zap.L().Error(fmt.Sprintf("新增node失败, %v", tx.Error))
 		return errors.New(fmt.Sprintf("新增node失败, %v", tx.Error))
 	}
 	return nil
+}
+
+// Update 更新node的所有字段，已软删除的记录会被恢复
+func (n *node) Update(node *model.Node) (err error) {
+	node.DeletedAt = gorm.DeletedAt{}
+	tx := mysql.DB.Unscoped().Save(&node)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("更新node失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("更新node失败, %v", tx.Error))
+	}
+	return nil
+}
+
+// Delete 软删除node，节点从集群中移除时调用
+func (n *node) Delete(cluster, hostName string) (err error) {
+	tx := mysql.DB.Where("cluster = ? and host_name = ?", cluster, hostName).Delete(&model.Node{})
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("删除node失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("删除node失败, %v", tx.Error))
+	}
+	return nil
 }
\ No newline at end of file
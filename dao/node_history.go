@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"k8sManagerApi/db/mysql"
+	"k8sManagerApi/model"
+)
+
+var NodeHistory nodeHistory
+
+type nodeHistory struct{}
+
+// NodeHistories 定义node变更记录列表的结构体
+type NodeHistories struct {
+	Total int64                `json:"total"`
+	Items []*model.NodeHistory `json:"items"`
+}
+
+// GetHistories 获取集群的node变更记录，hostName为空时返回集群所有node的记录
+func (n *nodeHistory) GetHistories(cluster, hostName string, page, limit int) (histories *NodeHistories, err error) {
+	// 定义分页数据的起始位置
+	startSet := (page - 1) * limit
+	// 定义数据库查询返回的内容
+	var (
+		historyList       = make([]*model.NodeHistory, 0)
+		total       int64 = 0
+	)
+	tx := mysql.DB.Model(&model.NodeHistory{}).Where("cluster = ?", cluster)
+	if hostName != "" {
+		tx = tx.Where("host_name = ?", hostName)
+	}
+	// 数据库查询
+	tx = tx.Count(&total).
+		Limit(limit).
+		Offset(startSet).
+		Order("id desc").
+		Find(&historyList)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("获取node变更记录失败, %v", tx.Error))
+		return nil, errors.New(fmt.Sprintf("获取node变更记录失败, %v", tx.Error))
+	}
+	histories = &NodeHistories{
+		Items: historyList,
+		Total: total,
+	}
+	return histories, nil
+}
+
+// Add 新增node变更记录
+func (n *nodeHistory) Add(history *model.NodeHistory) (err error) {
+	tx := mysql.DB.Create(&history)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("新增node变更记录失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("新增node变更记录失败, %v", tx.Error))
+	}
+	return nil
+}
\ No newline at end of file
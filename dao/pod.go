@@ -4,51 +4,106 @@ import (
 	"errors"
 	"fmt"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 	"k8sManagerApi/db/mysql"
 	"k8sManagerApi/model"
+	"time"
 )
 
 var PodInfo podInfo
 
 type podInfo struct{}
 
-// Add Pod信息
+// PodInfos 定义Pod生命周期记录列表的结构体
+type PodInfos struct {
+	Total int64            `json:"total"`
+	Items []*model.PodInfo `json:"items"`
+}
+
+// PodInfoQuery Pod生命周期记录的查询条件，为空的条件不参与过滤
+type PodInfoQuery struct {
+	Cluster   string
+	Namespace string
+	OwnerKind string
+	OwnerName string
+	NodeName  string
+	// StartTime、EndTime 查询在该时间段内存活过的Pod
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// GetPodInfos 获取Pod生命周期记录，支持按工作负载、节点及时间段查询
+func (p *podInfo) GetPodInfos(query *PodInfoQuery, page, limit int) (podInfos *PodInfos, err error) {
+	// 定义分页数据的起始位置
+	startSet := (page - 1) * limit
+	// 定义数据库查询返回的内容
+	var (
+		podInfoList       = make([]*model.PodInfo, 0)
+		total       int64 = 0
+	)
+	tx := mysql.DB.Model(&model.PodInfo{}).Where("cluster = ?", query.Cluster)
+	if query.Namespace != "" {
+		tx = tx.Where("namespace = ?", query.Namespace)
+	}
+	if query.OwnerKind != "" {
+		tx = tx.Where("owner_kind = ?", query.OwnerKind)
+	}
+	if query.OwnerName != "" {
+		tx = tx.Where("owner_name = ?", query.OwnerName)
+	}
+	if query.NodeName != "" {
+		tx = tx.Where("node_name = ?", query.NodeName)
+	}
+	if !query.EndTime.IsZero() {
+		tx = tx.Where("creation_time <= ?", query.EndTime)
+	}
+	if !query.StartTime.IsZero() {
+		tx = tx.Where("(deletion_time is null or deletion_time >= ?)", query.StartTime)
+	}
+	// 数据库查询
+	tx = tx.Count(&total).
+		Limit(limit).
+		Offset(startSet).
+		Order("id desc").
+		Find(&podInfoList)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("获取Pod记录失败, %v", tx.Error))
+		return nil, errors.New(fmt.Sprintf("获取Pod记录失败, %v", tx.Error))
+	}
+	podInfos = &PodInfos{
+		Items: podInfoList,
+		Total: total,
+	}
+	return podInfos, nil
+}
+
+// GetAlivePods 获取集群中未记录删除时间的Pod，pod监听任务启动时加载到内存中，并用于补记服务停止期间删除的Pod
+func (p *podInfo) GetAlivePods(cluster string) (pods []*model.PodInfo, err error) {
+	tx := mysql.DB.Model(&model.PodInfo{}).
+		Where("cluster = ? and uid <> '' and deletion_time is null", cluster).
+		Find(&pods)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("获取Pod记录失败, %v", tx.Error))
+		return nil, errors.New(fmt.Sprintf("获取Pod记录失败, %v", tx.Error))
+	}
+	return pods, nil
+}
+
+// Add 新增Pod记录
 func (p *podInfo) Add(pod *model.PodInfo) (err error) {
-	// 先判断Pod是否存在，如果存在则不添加
-	existingPod := &model.PodInfo{}
-	result := mysql.DB.Where("pod_name = ? and cluster = ? and pod_ip = ? and host_ip = ? and creation_time = ?",
-		pod.PodName, pod.Cluster, pod.PodIP, pod.HostIP, pod.CreationTime).First(&existingPod)
-
-	if result == nil {
-		// 如果Pod已存在，便在判断Pod的状态是否相等，如果相等，则不创建，否则只是更新状态。
-		res := mysql.DB.Where("pod_name = ? and cluster = ? and pod_ip = ? and host_ip = ? and creation_time = ? and status = ?",
-			pod.PodName, pod.Cluster, pod.PodIP, pod.HostIP, pod.CreationTime, pod.Status).First(&existingPod)
-		if res == nil {
-			zap.L().Warn(fmt.Sprintf("pod已存在, cluster: %v, name: %v", pod.Cluster, pod.PodName))
-			return nil
-		}
-		// 如果pod只是状态发生了改变，则只是修改状态即可。
-		mysql.DB.Model(&existingPod).Update("status", pod.Status)
-
-	} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		// 查询出错
-		zap.L().Error(fmt.Sprintf("查询Pod失败: %v", result.Error))
-		return errors.New(fmt.Sprintf("查询Pod失败: %v", result.Error))
-	}
-
-	// 插入新节点
 	tx := mysql.DB.Create(&pod)
 	if tx.Error != nil {
-		//zap.L().Error(fmt.Sprintf("新增Pod失败, %v", tx.Error))
-		return errors.New(fmt.Sprintf("新增Pod失败, %v", tx.Error))
+		zap.L().Error(fmt.Sprintf("新增Pod记录失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("新增Pod记录失败, %v", tx.Error))
 	}
 	return nil
 }
 
-// Del 删除Pod信息
-func (p *podInfo) Del(pod *model.PodInfo) (err error) {
-	delPod := model.PodInfo{}
-	mysql.DB.Debug().Where("pod_name = ? and cluster = ?", pod.PodName, pod.Cluster).Delete(&delPod)
+// Update 更新Pod记录的所有字段
+func (p *podInfo) Update(pod *model.PodInfo) (err error) {
+	tx := mysql.DB.Save(&pod)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("更新Pod记录失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("更新Pod记录失败, %v", tx.Error))
+	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"k8sManagerApi/db/mysql"
+	"k8sManagerApi/model"
+)
+
+var PodPhase podPhase
+
+type podPhase struct{}
+
+// GetPodPhases 获取单个Pod的状态变化记录，按时间先后排序
+func (p *podPhase) GetPodPhases(cluster, uid string) (phases []*model.PodPhase, err error) {
+	phases = make([]*model.PodPhase, 0)
+	tx := mysql.DB.Model(&model.PodPhase{}).
+		Where("cluster = ? and uid = ?", cluster, uid).
+		Order("id asc").
+		Find(&phases)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("获取Pod状态变化记录失败, %v", tx.Error))
+		return nil, errors.New(fmt.Sprintf("获取Pod状态变化记录失败, %v", tx.Error))
+	}
+	return phases, nil
+}
+
+// Add 新增Pod状态变化记录
+func (p *podPhase) Add(phase *model.PodPhase) (err error) {
+	tx := mysql.DB.Create(&phase)
+	if tx.Error != nil {
+		zap.L().Error(fmt.Sprintf("新增Pod状态变化记录失败, %v", tx.Error))
+		return errors.New(fmt.Sprintf("新增Pod状态变化记录失败, %v", tx.Error))
+	}
+	return nil
+}
\ No newline at end of file
@@ -26,13 +26,14 @@ func Init() {
 	}
 
 	//	连接MySQL数据库
+	mysqlInfo := config.Get().MysqlInfo
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
-		config.Conf.MysqlInfo.Username,
-		config.Conf.MysqlInfo.Password,
-		config.Conf.MysqlInfo.Host,
-		config.Conf.MysqlInfo.Port,
-		config.Conf.MysqlInfo.Database,
-		config.Conf.MysqlInfo.Charset)
+		mysqlInfo.Username,
+		mysqlInfo.Password,
+		mysqlInfo.Host,
+		mysqlInfo.Port,
+		mysqlInfo.Database,
+		mysqlInfo.Charset)
 
 	// SQL日志输出
 	newLogger := logger.New(
@@ -18,16 +18,17 @@ import (
 
 var lg *zap.Logger
 
+// level 日志级别，使用AtomicLevel以便配置文件变更时直接修改，无需重建logger
+var level = zap.NewAtomicLevel()
+
 // InitLogger 初始化Logger
 func InitLogger(cfg *config.LogConfig) (err error) {
 	writeSyncer := getLogWriter(cfg.Filename, cfg.MaxSize, cfg.MaxBackups, cfg.MaxAge, cfg.Compress)
 	encoder := getEncoder()
-	var l = new(zapcore.Level)
-	err = l.UnmarshalText([]byte(cfg.Level))
-	if err != nil {
+	if err = SetLevel(cfg.Level); err != nil {
 		return err
 	}
-	core := zapcore.NewCore(encoder, writeSyncer, l) // 只是写入文件
+	core := zapcore.NewCore(encoder, writeSyncer, level) // 只是写入文件
 
 	//consoleSyncer := zapcore.AddSync(os.Stdout)                                                  // 终端输出
 	//core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(writeSyncer, consoleSyncer), l) // 同时写入文件和终端输出
@@ -37,6 +38,23 @@ func InitLogger(cfg *config.LogConfig) (err error) {
 	return nil
 }
 
+// SetLevel 修改日志级别，立即生效
+func SetLevel(l string) error {
+	return level.UnmarshalText([]byte(l))
+}
+
+// OnConfigChange 配置文件变更时同步日志级别，文件名、切割等配置需要重启后生效
+func OnConfigChange(oldConf, newConf *config.ServerConfig) {
+	if newConf.LogConfig == nil || (oldConf.LogConfig != nil && oldConf.LogConfig.Level == newConf.LogConfig.Level) {
+		return
+	}
+	if err := SetLevel(newConf.LogConfig.Level); err != nil {
+		zap.L().Error(fmt.Sprintf("修改日志级别失败, %v", err))
+		return
+	}
+	zap.L().Info("log level changed", zap.String("level", newConf.LogConfig.Level))
+}
+
 // getLogWriter 获取日志写入器
 func getLogWriter(fileName string, maxSize, maxBackup, maxAge int, compress bool) zapcore.WriteSyncer {
 	// 拼接日志存放路径，存放在项目的logs目录下，文件名以配置的为准
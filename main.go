@@ -23,10 +23,10 @@ func main() {
 	config.Init()
 
 	//// 测试配置文件是否成功加载
-	//fmt.Println("Listen Addr: ", config.Conf.ListenAddr)
+	//fmt.Println("Listen Addr: ", config.Get().ListenAddr)
 
 	// 初始化日志
-	if err := logger.InitLogger(config.Conf.LogConfig); err != nil {
+	if err := logger.InitLogger(config.Get().LogConfig); err != nil {
 		fmt.Printf("init logger failed, err:%v\n", err.Error())
 		return
 	}
@@ -38,6 +38,10 @@ func main() {
 	service.K8s.Init()
 	//service.K8sA.Init()
 
+	// 配置文件热加载，增删集群、修改日志级别无需重启服务
+	config.OnChange(logger.OnConfigChange)
+	config.OnChange(service.K8s.OnConfigChange)
+
 	gin.SetMode(gin.ReleaseMode)
 	//	初始化gin
 	r := gin.Default()
@@ -54,20 +58,15 @@ func main() {
 
 	// 终端websocket
 	go func() {
-		http.HandleFunc(config.Conf.WSPath, service.Terminal.WebsocketHandler)
-		http.ListenAndServe(config.Conf.WSAddr, nil)
+		http.HandleFunc(config.Get().WSPath, service.Terminal.WebsocketHandler)
+		http.ListenAndServe(config.Get().WSAddr, nil)
 	}()
 
 	// event任务，用于监听event并写入数据库，这里传入的参数是集群名，与config配置文件中集群名对齐
 	//go func() {
 	//	service.Event.WatchEventTask("TST-1")
 	//}()
-	// event任务，用于监听event并写入数据库，循环从配置文件中读取集群名，启动g oroutin 任务
-	for _, cInfo := range config.Conf.KubeConfigs {
-		go func(clusterName string) {
-			service.Event.WatchEventTask(clusterName)
-		}(cInfo.Name)
-	}
+	// event任务在service.K8s.Init中随集群client一起启动，集群通过接口或配置文件增删时同步启停
 
 	// 数据库测试
 	//data, _ := dao.User.GetUserByName("zhangsan")
@@ -76,7 +75,7 @@ func main() {
 	//	启动gin server
 	//r.Run(config.ListerAddr)
 	srv := &http.Server{
-		Addr:    config.Conf.ListenAddr,
+		Addr:    config.Get().ListenAddr,
 		Handler: r,
 	}
 	go func() {
@@ -85,9 +84,9 @@ func main() {
 		}
 	}()
 
-	zap.L().Info("start gin server success", zap.String("listen", config.Conf.ListenAddr))
+	zap.L().Info("start gin server success", zap.String("listen", config.Get().ListenAddr))
 	// 等待中断信号，优雅关闭所有server
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
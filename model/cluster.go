@@ -0,0 +1,40 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Cluster 通过接口注册的集群，kubeconfig内容保存在数据库中，服务重启后重新加载
+type Cluster struct {
+	ID        uint           `json:"id" gorm:"primary_key"`
+	CreatedAt *time.Time     `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Name       string `json:"name"`                                  // 集群名，与集群注册表中的key对齐
+	KubeConfig string `json:"-" gorm:"column:kube_config;type:text"` // kubeconfig文件内容
+	Version    string `json:"version" gorm:"column:version"`         // 注册时获取到的集群版本 v1.24.2
+	Server     string `json:"server" gorm:"column:server"`           // apiserver地址
+	Describe   string `json:"describe" gorm:"column:describe"`       // 集群描述
+}
+
+func (*Cluster) TableName() string {
+	return "k8s_cluster"
+}
+
+/*
+CREATE TABLE `k8s_cluster` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(64) NOT NULL,
+  `kube_config` TEXT,
+  `version` VARCHAR(64) DEFAULT NULL,
+  `server` VARCHAR(255) DEFAULT NULL,
+  `describe` VARCHAR(255) DEFAULT NULL,
+  `created_at` datetime DEFAULT NULL,
+  `updated_at` datetime DEFAULT NULL,
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_k8s_cluster_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+*/
\ No newline at end of file
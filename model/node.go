@@ -11,17 +11,17 @@ type Node struct {
 	UpdatedAt *time.Time     `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	Cluster        string `json:"cluster"`                      // 所属集群
-	HostName       string `json:"name" gorm:"column:host_name"` // 主机名
-	IP             string `json:"ip"`                           // 服务器IP地址
-	Master         uint   `json:"master"`                       // 是否为master 1表示master 0表示work节点
-	CPU            int    `json:"cpu"`                          // CPU核数
-	Memory         string `json:"memory"`                       // 内存 3484264Ki
-	System         string `json:"system"`                       // 服务器系统  linux
-	OsImage        string `json:"os_image" gorm:"os_image"`     // 系统版本 CentOS Linux 7 (Core)
-	Arch           string `json:"arch"`                         // 服务器架构 amd64
-	KernelVersion  string `json:"kernel_version"`               // 服务器系统内核版本
-	KubeletVersion string `json:"kubelet_version"`              // kubelet版本
+	Cluster        string `json:"cluster" gorm:"uniqueIndex:uniq_node_cluster_host_name"`               // 所属集群
+	HostName       string `json:"name" gorm:"column:host_name;uniqueIndex:uniq_node_cluster_host_name"` // 主机名，同一集群内唯一
+	IP             string `json:"ip"`                                                                   // 服务器IP地址
+	Master         uint   `json:"master"`                                                               // 是否为master 1表示master 0表示work节点
+	CPU            int    `json:"cpu"`                                                                  // CPU核数
+	Memory         string `json:"memory"`                                                               // 内存 3484264Ki
+	System         string `json:"system"`                                                               // 服务器系统  linux
+	OsImage        string `json:"os_image" gorm:"os_image"`                                             // 系统版本 CentOS Linux 7 (Core)
+	Arch           string `json:"arch"`                                                                 // 服务器架构 amd64
+	KernelVersion  string `json:"kernel_version"`                                                       // 服务器系统内核版本
+	KubeletVersion string `json:"kubelet_version"`                                                      // kubelet版本
 
 }
 
@@ -47,6 +47,7 @@ CREATE TABLE `node` (
   `updated_at` datetime DEFAULT NULL,
   `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_node_cluster_host_name` (`cluster`, `host_name`),
   KEY `idx_node_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB AUTO_INCREMENT=2291 CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 */
\ No newline at end of file
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 节点变更类型
+const (
+	NodeActionAdd    = "Add"    // 节点加入集群，或下线后重新加入
+	NodeActionUpdate = "Update" // 节点属性变化，如kubelet升级、内核变更、容量变化
+	NodeActionDelete = "Delete" // 节点从集群中移除
+)
+
+// NodeHistory node变更记录，Update时每个变化的字段记录一条
+type NodeHistory struct {
+	ID        uint           `json:"id" gorm:"primary_key"`
+	CreatedAt *time.Time     `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Cluster    string     `json:"cluster"`                      // 所属集群
+	HostName   string     `json:"name" gorm:"column:host_name"` // 主机名
+	Action     string     `json:"action"`                       // Add、Update、Delete
+	Field      string     `json:"field"`                        // 变化的字段 kubelet_version，Add及Delete时为空
+	OldValue   string     `json:"old_value"`                    // 变化前的值
+	NewValue   string     `json:"new_value"`                    // 变化后的值
+	ChangeTime *time.Time `json:"change_time"`                  // 变更被发现的时间
+}
+
+func (*NodeHistory) TableName() string {
+	return "node_history"
+}
+
+/*
+CREATE TABLE `node_history` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `cluster` VARCHAR(255) DEFAULT NULL,
+  `host_name` VARCHAR(255) DEFAULT NULL,
+  `action` VARCHAR(32) DEFAULT NULL,
+  `field` VARCHAR(64) DEFAULT NULL,
+  `old_value` VARCHAR(255) DEFAULT NULL,
+  `new_value` VARCHAR(255) DEFAULT NULL,
+  `change_time` datetime DEFAULT NULL,
+  `created_at` datetime DEFAULT NULL,
+  `updated_at` datetime DEFAULT NULL,
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_node_history_cluster_host_name` (`cluster`, `host_name`),
+  KEY `idx_node_history_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+*/
\ No newline at end of file
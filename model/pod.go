@@ -5,18 +5,28 @@ import (
 	"time"
 )
 
+// PodInfo Pod的生命周期记录，由informer维护，Pod删除后保留记录并填写删除时间
 type PodInfo struct {
 	ID        uint           `json:"id" gorm:"primary_key"`
 	CreatedAt *time.Time     `json:"created_at"`
 	UpdatedAt *time.Time     `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	Cluster      string    `json:"cluster"`                             // 所属集群
-	PodName      string    `json:"pod_name" gorm:"column:pod_name"`     // pod名字
-	HostIP       string    `json:"host_ip" gorm:"column:host_ip"`       // 所在节点IP
-	PodIP        string    `json:"pod_ip" gorm:"pod_ip"`                // Pod IP
-	Status       string    `json:"status"`                              // pod状态
-	CreationTime time.Time `json:"creation_time" gorm:"type:timestamp"` // Pod的创建时间
+	Cluster           string     `json:"cluster"`                             // 所属集群
+	Namespace         string     `json:"namespace"`                           // 所属namespace
+	PodName           string     `json:"pod_name" gorm:"column:pod_name"`     // pod名字
+	UID               string     `json:"uid" gorm:"column:uid"`               // pod的uid，同名pod重建后uid不同
+	OwnerKind         string     `json:"owner_kind"`                          // 所属工作负载类型 Deployment、StatefulSet、DaemonSet、Job
+	OwnerName         string     `json:"owner_name"`                          // 所属工作负载名称
+	NodeName          string     `json:"node_name"`                           // 调度到的节点
+	HostIP            string     `json:"host_ip" gorm:"column:host_ip"`       // 所在节点IP
+	PodIP             string     `json:"pod_ip" gorm:"pod_ip"`                // Pod IP
+	Status            string     `json:"status"`                              // pod状态，即Phase
+	RestartCount      int        `json:"restart_count"`                       // 所有容器的重启次数之和
+	TerminationReason string     `json:"termination_reason"`                  // 最近一次终止的原因 OOMKilled、Error、Evicted
+	CreationTime      time.Time  `json:"creation_time" gorm:"type:timestamp"` // Pod的创建时间
+	StartTime         *time.Time `json:"start_time"`                          // Pod被kubelet接收的时间
+	DeletionTime      *time.Time `json:"deletion_time"`                       // Pod的删除时间，未删除时为空
 }
 
 func (*PodInfo) TableName() string {
@@ -27,15 +37,26 @@ func (*PodInfo) TableName() string {
 CREATE TABLE `pod_info` (
   `id` int NOT NULL AUTO_INCREMENT,
   `cluster` VARCHAR(255) DEFAULT NULL,
+  `namespace` VARCHAR(255) DEFAULT NULL,
   `pod_name` VARCHAR(255) DEFAULT NULL,
+  `uid` VARCHAR(64) DEFAULT NULL,
+  `owner_kind` VARCHAR(64) DEFAULT NULL,
+  `owner_name` VARCHAR(255) DEFAULT NULL,
+  `node_name` VARCHAR(255) DEFAULT NULL,
   `host_ip` VARCHAR(64) DEFAULT NULL,
   `pod_ip` VARCHAR(64) DEFAULT NULL,
   `status` VARCHAR(64) DEFAULT NULL,
+  `restart_count` int DEFAULT 0,
+  `termination_reason` VARCHAR(255) DEFAULT NULL,
   `creation_time` datetime DEFAULT NULL,
+  `start_time` datetime DEFAULT NULL,
+  `deletion_time` datetime DEFAULT NULL,
   `created_at` datetime DEFAULT NULL,
   `updated_at` datetime DEFAULT NULL,
   `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
+  KEY `idx_pod_info_cluster_uid` (`cluster`, `uid`),
+  KEY `idx_pod_info_owner` (`cluster`, `namespace`, `owner_name`),
   KEY `idx_pod_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB AUTO_INCREMENT=2291 CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 */
\ No newline at end of file
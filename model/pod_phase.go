@@ -0,0 +1,47 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// PodPhase Pod的状态变化记录，Pod首次被发现时OldPhase为空
+type PodPhase struct {
+	ID        uint           `json:"id" gorm:"primary_key"`
+	CreatedAt *time.Time     `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Cluster        string     `json:"cluster"`                         // 所属集群
+	Namespace      string     `json:"namespace"`                       // 所属namespace
+	PodName        string     `json:"pod_name" gorm:"column:pod_name"` // pod名字
+	UID            string     `json:"uid" gorm:"column:uid"`           // pod的uid
+	OldPhase       string     `json:"old_phase"`                       // 变化前的状态
+	NewPhase       string     `json:"new_phase"`                       // 变化后的状态，Pod删除时为Deleted
+	Reason         string     `json:"reason"`                          // 状态变化的原因
+	TransitionTime *time.Time `json:"transition_time"`                 // 状态变化被发现的时间
+}
+
+func (*PodPhase) TableName() string {
+	return "pod_phase"
+}
+
+/*
+CREATE TABLE `pod_phase` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `cluster` VARCHAR(255) DEFAULT NULL,
+  `namespace` VARCHAR(255) DEFAULT NULL,
+  `pod_name` VARCHAR(255) DEFAULT NULL,
+  `uid` VARCHAR(64) DEFAULT NULL,
+  `old_phase` VARCHAR(64) DEFAULT NULL,
+  `new_phase` VARCHAR(64) DEFAULT NULL,
+  `reason` VARCHAR(255) DEFAULT NULL,
+  `transition_time` datetime DEFAULT NULL,
+  `created_at` datetime DEFAULT NULL,
+  `updated_at` datetime DEFAULT NULL,
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_pod_phase_cluster_uid` (`cluster`, `uid`),
+  KEY `idx_pod_phase_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+*/
\ No newline at end of file
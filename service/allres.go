@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 	"sync"
 )
 
@@ -14,8 +13,8 @@ type allRes struct{}
 // 定义一个全局互斥锁
 var mt sync.Mutex
 
-// GetAllNum 获取集群的所有资源
-func (a *allRes) GetAllNum(client *kubernetes.Clientset) (map[string]int, []error) {
+// GetAllNum 获取集群的所有资源，Job和CronJob以外的资源从informer缓存获取
+func (a *allRes) GetAllNum(cc *ClusterClient) (map[string]int, []error) {
 	var wg sync.WaitGroup
 	wg.Add(14)
 	errs := make([]error, 0)
@@ -23,97 +22,109 @@ func (a *allRes) GetAllNum(client *kubernetes.Clientset) (map[string]int, []erro
 
 	// 获取所有的node节点
 	go func() {
-		list, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListNodes()
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Nodes", len(list.Items))
+		addMap(data, "Nodes", len(list))
 		wg.Done()
 	}()
 	// 获取所有的Namespace
 	go func() {
-		list, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListNamespaces()
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Namespaces", len(list.Items))
+		addMap(data, "Namespaces", len(list))
 		wg.Done()
 	}()
 	// 获取所有的PV
 	go func() {
-		list, err := client.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListPvs()
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "PVs", len(list.Items))
+		addMap(data, "PVs", len(list))
 		wg.Done()
 	}()
 	// 获取所有的PVC
 	go func() {
-		list, err := client.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListPvcs("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "PVCs", len(list.Items))
+		addMap(data, "PVCs", len(list))
 		wg.Done()
 	}()
 	// 获取所有的Service
 	go func() {
-		list, err := client.CoreV1().Services("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListServices("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Services", len(list.Items))
+		addMap(data, "Services", len(list))
 		wg.Done()
 	}()
-	// 获取所有的Ingress
+	// 获取所有的Ingress，集群不支持的资源不统计
 	go func() {
-		list, err := client.NetworkingV1().Ingresses("").List(context.TODO(), metav1.ListOptions{})
+		if ok, _ := Capability.IsSupported(cc, IngressGVR); !ok {
+			wg.Done()
+			return
+		}
+		list, err := cc.Cache.ListIngresses("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Ingresses", len(list.Items))
+		addMap(data, "Ingresses", len(list))
 		wg.Done()
 	}()
 	// 获取所有的Deployment
 	go func() {
-		list, err := client.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListDeployments("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Deployments", len(list.Items))
+		addMap(data, "Deployments", len(list))
 		wg.Done()
 	}()
 	// 获取所有的DaemonSet
 	go func() {
-		list, err := client.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListDaemonSets("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "DaemonSets", len(list.Items))
+		addMap(data, "DaemonSets", len(list))
 		wg.Done()
 	}()
 	// 获取所有的StatefulSet
 	go func() {
-		list, err := client.AppsV1().StatefulSets("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListStatefulSets("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "StatefulSets", len(list.Items))
+		addMap(data, "StatefulSets", len(list))
 		wg.Done()
 	}()
 	// 获取所有的Job
 	go func() {
-		list, err := client.BatchV1().Jobs("").List(context.TODO(), metav1.ListOptions{})
+		if ok, _ := Capability.IsSupported(cc, JobGVR); !ok {
+			wg.Done()
+			return
+		}
+		list, err := cc.ClientSet.BatchV1().Jobs("").List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
 			errs = append(errs, err)
 		}
 		addMap(data, "Jobs", len(list.Items))
 		wg.Done()
 	}()
-	// 获取所有的CronJobs
+	// 获取所有的CronJobs，1.21以前的集群没有batch/v1的CronJob
 	go func() {
-		list, err := client.BatchV1().CronJobs("").List(context.TODO(), metav1.ListOptions{})
+		if ok, _ := Capability.IsSupported(cc, CronJobGVR); !ok {
+			wg.Done()
+			return
+		}
+		list, err := cc.ClientSet.BatchV1().CronJobs("").List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -122,29 +133,29 @@ func (a *allRes) GetAllNum(client *kubernetes.Clientset) (map[string]int, []erro
 	}()
 	// 获取所有的Pod
 	go func() {
-		list, err := client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListPods("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Pods", len(list.Items))
+		addMap(data, "Pods", len(list))
 		wg.Done()
 	}()
 	// 获取所有的Secrets
 	go func() {
-		list, err := client.CoreV1().Secrets("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListSecrets("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "Secrets", len(list.Items))
+		addMap(data, "Secrets", len(list))
 		wg.Done()
 	}()
 	// 获取所有的ConfigMap
 	go func() {
-		list, err := client.CoreV1().ConfigMaps("").List(context.TODO(), metav1.ListOptions{})
+		list, err := cc.Cache.ListConfigMaps("")
 		if err != nil {
 			errs = append(errs, err)
 		}
-		addMap(data, "ConfigMaps", len(list.Items))
+		addMap(data, "ConfigMaps", len(list))
 		wg.Done()
 	}()
 
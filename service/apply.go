@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"time"
+)
+
+var Apply apply
+
+type apply struct{}
+
+// DefaultFieldManager 未指定fieldManager时使用的字段管理者名称
+const DefaultFieldManager = "k8s-manager-api"
+
+// apply CRD后等待其可用的轮询间隔及超时时间
+const (
+	crdPollInterval     = 500 * time.Millisecond
+	crdEstablishTimeout = 10 * time.Second
+)
+
+// 单个资源的apply结果，与kubectl apply的输出一致
+const (
+	ApplyCreated    = "created"
+	ApplyConfigured = "configured"
+	ApplyUnchanged  = "unchanged"
+	ApplyFailed     = "failed"
+)
+
+// ApplyResp apply的返回内容，Results与manifest中资源的顺序一致
+type ApplyResp struct {
+	DryRun  bool           `json:"dry_run"`
+	Results []*ApplyResult `json:"results"`
+	// Counts 各结果的资源数量，key为created、configured、unchanged、failed
+	Counts map[string]int `json:"counts"`
+}
+
+// ApplyResult 单个资源的apply结果，Message为失败原因
+type ApplyResult struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Result     string `json:"result"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Apply 使用server-side apply依次应用manifest中的资源，单个资源失败不影响其它资源
+// namespace为未指定namespace的资源使用的默认值，force为true时强制接管其它fieldManager的字段
+func (a *apply) Apply(cc *ClusterClient, namespace, content, fieldManager string, force, dryRun bool) (*ApplyResp, error) {
+	objs, err := decodeObjects(content)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
+		return nil, badRequest("反序列化失败, " + err.Error())
+	}
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	opts := metav1.ApplyOptions{FieldManager: fieldManager, Force: force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	resp := &ApplyResp{
+		DryRun:  dryRun,
+		Results: make([]*ApplyResult, 0, len(objs)),
+		Counts:  map[string]int{ApplyCreated: 0, ApplyConfigured: 0, ApplyUnchanged: 0, ApplyFailed: 0},
+	}
+	for _, obj := range objs {
+		result := a.applyObject(cc, obj, namespace, opts)
+		resp.Results = append(resp.Results, result)
+		resp.Counts[result.Result]++
+	}
+	return resp, nil
+}
+
+// applyObject 应用单个资源，apply前后的内容去掉集群维护的字段后相同时为unchanged
+func (a *apply) applyObject(cc *ClusterClient, obj *unstructured.Unstructured, namespace string, opts metav1.ApplyOptions) *ApplyResult {
+	result := &ApplyResult{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+	fail := func(err error) *ApplyResult {
+		zap.L().Error(fmt.Sprintf("apply %s %s失败, %v", result.Kind, result.Name, err.Error()))
+		result.Result = ApplyFailed
+		result.Message = err.Error()
+		return result
+	}
+	if result.APIVersion == "" || result.Kind == "" {
+		return fail(errors.New("缺少apiVersion或kind"))
+	}
+	if result.Name == "" {
+		return fail(errors.New("缺少metadata.name"))
+	}
+	mapping, err := Generic.ResolveKind(cc, obj.GroupVersionKind())
+	if err != nil {
+		return fail(err)
+	}
+	if !mapping.Namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	result.Namespace = obj.GetNamespace()
+	// server-side apply不允许请求中带managedFields
+	obj.SetManagedFields(nil)
+
+	client := Generic.client(cc, mapping, obj.GetNamespace())
+	live, err := client.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fail(err)
+	}
+	exists := err == nil
+	applied, err := client.Apply(context.TODO(), obj.GetName(), obj, opts)
+	if err != nil {
+		return fail(err)
+	}
+	// manifest中CRD之后的自定义资源需要识别新的类型，等CRD可用后刷新discovery缓存
+	if mapping.GVR.GroupResource() == CRDGVR.GroupResource() && len(opts.DryRun) == 0 {
+		a.waitEstablished(client, applied.GetName())
+		cc.resetDiscovery()
+	}
+	switch {
+	case !exists:
+		result.Result = ApplyCreated
+	case a.unchanged(live, applied):
+		result.Result = ApplyUnchanged
+	default:
+		result.Result = ApplyConfigured
+	}
+	return result
+}
+
+// waitEstablished 等待CRD的Established条件为True，超时后不再等待，后续资源解析失败时返回原因
+func (a *apply) waitEstablished(client dynamic.ResourceInterface, name string) {
+	err := wait.PollUntilContextTimeout(context.TODO(), crdPollInterval, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+		crd, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if ok && condition["type"] == "Established" && condition["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		zap.L().Warn(fmt.Sprintf("等待CRD %s可用超时, %v", name, err))
+	}
+}
+
+// unchanged 比较apply前后的资源，忽略status、managedFields及resourceVersion
+func (a *apply) unchanged(live, applied *unstructured.Unstructured) bool {
+	before, err := toCleanMap(live)
+	if err != nil {
+		return false
+	}
+	after, err := toCleanMap(applied)
+	if err != nil {
+		return false
+	}
+	unstructured.RemoveNestedField(before, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(after, "metadata", "resourceVersion")
+	return equality.Semantic.DeepEqual(before, after)
+}
\ No newline at end of file
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8sManagerApi/dao"
+	"k8sManagerApi/model"
+	"time"
+)
+
+var Cluster cluster
+
+type cluster struct{}
+
+// ClusterCreate 定义ClusterCreate结构体，用于注册集群需要的参数属性的定义
+type ClusterCreate struct {
+	Name       string `form:"name"`
+	Describe   string `form:"describe"`
+	KubeConfig []byte `form:"-"`
+}
+
+// CreateCluster 注册集群，校验kubeconfig可用后入库，并添加client及启动event监听
+func (c *cluster) CreateCluster(data *ClusterCreate) (err error) {
+	if data.Name == "" {
+		return badRequest("集群名不能为空")
+	}
+	// 只判断是否注册，不能用GetClient，不健康的集群也会返回错误
+	if K8s.HasCluster(data.Name) || K8s.IsStaticCluster(data.Name) {
+		zap.L().Warn(fmt.Sprintf("集群已存在: %s", data.Name))
+		return conflict(fmt.Sprintf("集群已存在: %s", data.Name))
+	}
+	_, has, err := dao.Cluster.HasCluster(data.Name)
+	if err != nil {
+		return err
+	}
+	if has {
+		zap.L().Warn(fmt.Sprintf("集群已存在: %s", data.Name))
+		return conflict(fmt.Sprintf("集群已存在: %s", data.Name))
+	}
+	// 校验kubeconfig，获取集群版本
+	conf, version, err := c.checkKubeConfig(data.KubeConfig)
+	if err != nil {
+		return err
+	}
+	newCluster := &model.Cluster{
+		Name:       data.Name,
+		KubeConfig: string(data.KubeConfig),
+		Version:    version,
+		Server:     conf.Host,
+		Describe:   data.Describe,
+	}
+	// 先在内存中注册，失败时不入库，避免残留的记录导致同名集群无法再次注册
+	if err = K8s.AddCluster(data.Name, conf); err != nil {
+		return err
+	}
+	if err = dao.Cluster.Add(newCluster); err != nil {
+		K8s.RemoveCluster(data.Name)
+		return err
+	}
+	return nil
+}
+
+// UpdateCluster 更新集群的kubeconfig，新client创建成功后替换旧client，入库失败时恢复旧client
+func (c *cluster) UpdateCluster(data *ClusterCreate) (err error) {
+	if K8s.IsStaticCluster(data.Name) {
+		return badRequest(fmt.Sprintf("集群%s定义在配置文件中，无法通过接口修改", data.Name))
+	}
+	oldCluster, has, err := dao.Cluster.HasCluster(data.Name)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return notFound(fmt.Sprintf("集群不存在: %s", data.Name))
+	}
+	conf, version, err := c.checkKubeConfig(data.KubeConfig)
+	if err != nil {
+		return err
+	}
+	oldConf, err := K8s.ReplaceCluster(data.Name, conf)
+	if err != nil {
+		return err
+	}
+	newCluster := *oldCluster
+	newCluster.KubeConfig = string(data.KubeConfig)
+	newCluster.Version = version
+	newCluster.Server = conf.Host
+	newCluster.Describe = data.Describe
+	if err = dao.Cluster.Update(&newCluster); err != nil {
+		if _, restoreErr := K8s.ReplaceCluster(data.Name, oldConf); restoreErr != nil {
+			zap.L().Error(fmt.Sprintf("集群%s: 恢复旧client失败, %v", data.Name, restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteCluster 删除集群，停止event监听并移除client
+func (c *cluster) DeleteCluster(name string) (err error) {
+	if K8s.IsStaticCluster(name) {
+		return badRequest(fmt.Sprintf("集群%s定义在配置文件中，无法通过接口删除", name))
+	}
+	_, has, err := dao.Cluster.HasCluster(name)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return notFound(fmt.Sprintf("集群不存在: %s", name))
+	}
+	K8s.RemoveCluster(name)
+	return dao.Cluster.DeleteByName(name)
+}
+
+// checkKubeConfig 解析kubeconfig并请求集群版本，确认集群可以连通
+func (c *cluster) checkKubeConfig(content []byte) (conf *rest.Config, version string, err error) {
+	conf, err = loadKubeConfig(content)
+	if err != nil {
+		return nil, "", err
+	}
+	// 校验时使用超时时间，避免集群不通时请求长时间挂起
+	checkConf := rest.CopyConfig(conf)
+	checkConf.Timeout = 5 * time.Second
+	clientSet, err := kubernetes.NewForConfig(checkConf)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("创建K8s client失败, %v", err.Error()))
+		return nil, "", errors.New("创建K8s client失败, " + err.Error())
+	}
+	info, err := clientSet.Discovery().ServerVersion()
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取集群版本失败, %v", err.Error()))
+		return nil, "", errors.New("获取集群版本失败, 请检查kubeconfig及网络, " + err.Error())
+	}
+	return conf, info.GitVersion, nil
+}
+
+// loadKubeConfig 解析通过接口上传的kubeconfig，只接受内联的证书、私钥及token
+// exec、auth-provider会在服务器上执行命令，client-certificate、tokenFile等路径字段会读取服务器上的文件，一律拒绝
+func loadKubeConfig(content []byte) (*rest.Config, error) {
+	kubeConfig, err := clientcmd.Load(content)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("解析kubeconfig失败, %v", err.Error()))
+		return nil, badRequest("解析kubeconfig失败, " + err.Error())
+	}
+	if err := checkKubeConfigSafe(kubeConfig); err != nil {
+		zap.L().Warn(fmt.Sprintf("kubeconfig校验失败, %v", err.Error()))
+		return nil, err
+	}
+	conf, err := clientcmd.NewDefaultClientConfig(*kubeConfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("解析kubeconfig失败, %v", err.Error()))
+		return nil, badRequest("解析kubeconfig失败, " + err.Error())
+	}
+	return conf, nil
+}
+
+// checkKubeConfigSafe 检查kubeconfig中的用户及集群，存在exec、auth-provider或文件路径时返回错误
+func checkKubeConfigSafe(kubeConfig *clientcmdapi.Config) error {
+	for name, authInfo := range kubeConfig.AuthInfos {
+		switch {
+		case authInfo.Exec != nil:
+			return badRequest(fmt.Sprintf("kubeconfig用户%s: 不支持exec插件, 请使用token或内联证书", name))
+		case authInfo.AuthProvider != nil:
+			return badRequest(fmt.Sprintf("kubeconfig用户%s: 不支持auth-provider, 请使用token或内联证书", name))
+		case authInfo.ClientCertificate != "", authInfo.ClientKey != "", authInfo.TokenFile != "":
+			return badRequest(fmt.Sprintf("kubeconfig用户%s: 不支持引用文件, 请使用client-certificate-data、client-key-data或token", name))
+		}
+	}
+	for name, cluster := range kubeConfig.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return badRequest(fmt.Sprintf("kubeconfig集群%s: 不支持引用文件, 请使用certificate-authority-data", name))
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ClusterHealth = &clusterHealth{
+	statusMap: map[string]*ClusterStatus{},
+}
+
+type clusterHealth struct {
+	statusMap map[string]*ClusterStatus
+	mu        sync.RWMutex
+}
+
+// 集群健康状态
+const (
+	ClusterStatusUnknown   = "Unknown"
+	ClusterStatusHealthy   = "Healthy"
+	ClusterStatusUnhealthy = "Unhealthy"
+)
+
+const (
+	// 探测间隔及单次探测的超时时间
+	healthProbeInterval = 15 * time.Second
+	healthProbeTimeout  = 5 * time.Second
+	// 连续失败次数达到阈值后，将集群标记为不可达
+	healthFailureThreshold = 2
+)
+
+// ClusterStatus 集群的健康状态，由后台探测任务定时更新
+type ClusterStatus struct {
+	Cluster         string     `json:"cluster"`
+	Status          string     `json:"status"`            // Unknown、Healthy、Unhealthy
+	Version         string     `json:"version"`           // 集群版本 v1.24.2
+	Latency         int64      `json:"latency"`           // 最近一次探测耗时，单位ms
+	LastError       string     `json:"last_error"`        // 最近一次探测失败的原因
+	FailureCount    int        `json:"failure_count"`     // 连续失败次数
+	LastProbeTime   *time.Time `json:"last_probe_time"`   // 最近一次探测时间
+	LastHealthyTime *time.Time `json:"last_healthy_time"` // 最近一次探测成功的时间
+}
+
+// GetStatusList 获取所有集群的健康状态，按集群名排序
+func (c *clusterHealth) GetStatusList() []*ClusterStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list := make([]*ClusterStatus, 0, len(c.statusMap))
+	for _, status := range c.statusMap {
+		// 返回副本，避免与探测任务并发读写
+		s := *status
+		list = append(list, &s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Cluster < list[j].Cluster
+	})
+	return list
+}
+
+// Check 集群被标记为不可达时返回503的APIError，用于handler快速失败，不再等待请求超时，前端可以与参数错误区分
+func (c *clusterHealth) Check(cluster string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	status, ok := c.statusMap[cluster]
+	if !ok || status.Status != ClusterStatusUnhealthy {
+		return nil
+	}
+	return &APIError{Code: http.StatusServiceUnavailable, Reason: metav1.StatusReasonServiceUnavailable, Message: fmt.Sprintf("集群不可达: %s, %s", cluster, status.LastError)}
+}
+
+// WatchHealthTask 定时探测集群apiserver的版本及/readyz，stopCh关闭时退出
+func (c *clusterHealth) WatchHealthTask(cluster string, client *kubernetes.Clientset, stopCh chan struct{}) {
+	status := &ClusterStatus{Cluster: cluster, Status: ClusterStatusUnknown}
+	c.mu.Lock()
+	c.statusMap[cluster] = status
+	c.mu.Unlock()
+
+	ticker := time.NewTicker(healthProbeInterval)
+	defer ticker.Stop()
+	for {
+		c.probe(cluster, client, status)
+		select {
+		case <-stopCh:
+			// 集群更新时会以同名重新启动探测任务，只清理自己写入的状态
+			c.mu.Lock()
+			if c.statusMap[cluster] == status {
+				delete(c.statusMap, cluster)
+			}
+			c.mu.Unlock()
+			zap.L().Info("stop watch health task", zap.String("cluster", cluster))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe 单次探测，请求/version获取版本，再请求/readyz确认apiserver就绪
+func (c *clusterHealth) probe(cluster string, client *kubernetes.Clientset, status *ClusterStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var info version.Info
+	body, err := client.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err == nil {
+		err = json.Unmarshal(body, &info)
+	}
+	if err == nil {
+		_, err = client.Discovery().RESTClient().Get().AbsPath("/readyz").Do(ctx).Raw()
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status.Latency = now.Sub(start).Milliseconds()
+	status.LastProbeTime = &now
+	if err != nil {
+		status.FailureCount++
+		status.LastError = err.Error()
+		if status.FailureCount >= healthFailureThreshold && status.Status != ClusterStatusUnhealthy {
+			status.Status = ClusterStatusUnhealthy
+			zap.L().Warn(fmt.Sprintf("集群 %s 不可达, %v", cluster, err))
+		}
+		return
+	}
+	if status.Status == ClusterStatusUnhealthy {
+		zap.L().Info(fmt.Sprintf("集群 %s 恢复可用", cluster))
+	}
+	status.Status = ClusterStatusHealthy
+	status.Version = info.GitVersion
+	status.FailureCount = 0
+	status.LastError = ""
+	status.LastHealthyTime = &now
+}
\ No newline at end of file
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const kubeConfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: https://127.0.0.1:6443
+%s
+users:
+- name: u
+  user:
+%s
+contexts:
+- name: ctx
+  context:
+    cluster: c
+    user: u
+current-context: ctx
+`
+
+func TestLoadKubeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster string
+		user    string
+		wantErr string
+	}{
+		{name: "inline token", user: "    token: abc"},
+		{name: "inline cert data", cluster: "    insecure-skip-tls-verify: true", user: "    client-certificate-data: Zm9v\n    client-key-data: YmFy"},
+		{name: "exec plugin", user: "    exec:\n      apiVersion: client.authentication.k8s.io/v1beta1\n      command: /bin/sh", wantErr: "exec"},
+		{name: "auth provider", user: "    auth-provider:\n      name: gcp", wantErr: "auth-provider"},
+		{name: "client certificate path", user: "    client-certificate: /etc/passwd", wantErr: "引用文件"},
+		{name: "client key path", user: "    client-key: /root/.ssh/id_rsa", wantErr: "引用文件"},
+		{name: "token file", user: "    tokenFile: /var/run/secrets/token", wantErr: "引用文件"},
+		{name: "certificate authority path", cluster: "    certificate-authority: /etc/ca.crt", user: "    token: abc", wantErr: "引用文件"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte(strings.Replace(strings.Replace(kubeConfigTemplate, "%s", tt.cluster, 1), "%s", tt.user, 1))
+			conf, err := loadKubeConfig(content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if conf.Host != "https://127.0.0.1:6443" {
+					t.Fatalf("host = %s", conf.Host)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want contains %q", err, tt.wantErr)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.Code != 400 {
+				t.Fatalf("error should be a 400 APIError, got %#v", err)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -18,44 +16,60 @@ type configMap struct{}
 type configMapResp struct {
 	Total int                `json:"total"`
 	Items []corev1.ConfigMap `json:"items"`
+	PageInfo
 }
 
 // GetConfigMaps 获取configmap列表
-func (c *configMap) GetConfigMaps(client *kubernetes.Clientset, namespaces, filterName string, limit, page int) (configMapRest *configMapResp, err error) {
+func (c *configMap) GetConfigMaps(cc *ClusterClient, namespace string, query *DataSelectQuery) (configMapRest *configMapResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().ConfigMaps(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取configMap列表失败, %v", err.Error()))
+			return nil, newAPIError("获取configMap列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(c.toCells(list.Items), list.ListMeta)
+		return &configMapResp{Total: total, Items: c.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取configmap
-	configMapList, err := client.CoreV1().ConfigMaps(namespaces).List(context.TODO(), metav1.ListOptions{})
+	configMapList, err := cc.Cache.ListConfigMaps(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取configMap列表失败, %v", err.Error()))
-		return nil, errors.New("获取configMap列表失败," + err.Error())
+		return nil, newAPIError("获取configMap列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: c.toCells(configMapList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: c.toCells(configMapList),
+		DataSelect:      query,
 	}
 	filtered := selectableData.Filter()
 	total := len(filtered.GenericDataList)
 	data := filtered.Sort().Paginate()
 	configmaps := c.fromCells(data.GenericDataList)
 	configMapRest = &configMapResp{
-		Total: total,
-		Items: configmaps,
+		Total:    total,
+		Items:    configmaps,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return configMapRest, nil
 }
 
+// GetConfigMapsAllClusters 跨集群获取ConfigMap列表
+func (c *configMap) GetConfigMapsAllClusters(namespace string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		configMaps, err := cc.Cache.ListConfigMaps(namespace)
+		if err != nil {
+			return nil, err
+		}
+		return c.toCells(configMaps), nil
+	}, query)
+}
+
 // GetConfigMapDetail 获取configMap详情
 func (c *configMap) GetConfigMapDetail(client *kubernetes.Clientset, namespace, configmapName string) (configMap *corev1.ConfigMap, err error) {
 	configMap, err = client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), configmapName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取ConfigMap详情失败, %v", err.Error()))
-		return nil, errors.New("获取ConfigMap详情失败, " + err.Error())
+		return nil, newAPIError("获取ConfigMap详情失败", err)
 	}
 	return configMap, nil
 }
@@ -63,15 +77,15 @@ func (c *configMap) GetConfigMapDetail(client *kubernetes.Clientset, namespace,
 // UpdateConfigMap 更新ConfigMap
 func (c *configMap) UpdateConfigMap(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var configMap = &corev1.ConfigMap{}
-	err = json.Unmarshal([]byte(content), configMap)
+	err = decodeContent(content, configMap)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新ConfigMap失败, %v", err.Error()))
-		return errors.New("更新ConfigMap失败, " + err.Error())
+		return newAPIError("更新ConfigMap失败", err)
 	}
 	return nil
 }
@@ -81,7 +95,7 @@ func (c *configMap) DeleteConfigMap(client *kubernetes.Clientset, namespace, con
 	err = client.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), configmapName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除ConfigMap失败, %v", err.Error()))
-		return errors.New("删除ConfigMap失败, " + err.Error())
+		return newAPIError("删除ConfigMap失败", err)
 	}
 	return nil
 }
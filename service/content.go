@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// 详情接口的返回格式，yaml为去掉managedFields、status等字段后的yaml，可以编辑后直接提交给更新接口
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// decodeContent 反序列化content，支持json及yaml，只能包含一个资源，多个资源需要通过apply接口逐个返回结果
+func decodeContent(content string, obj interface{}) error {
+	docs, err := splitContent(content)
+	if err != nil {
+		return err
+	}
+	if len(docs) != 1 {
+		return badRequest(fmt.Sprintf("content中只能包含一个资源, 实际为%d个, 多个资源请使用/api/k8s/apply", len(docs)))
+	}
+	return json.Unmarshal(docs[0], obj)
+}
+
+// decodeObjects 反序列化content中的所有资源，支持json及多文档yaml
+func decodeObjects(content string) ([]*unstructured.Unstructured, error) {
+	docs, err := splitContent(content)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]*unstructured.Unstructured, 0, len(docs))
+	for _, doc := range docs {
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(doc, &obj.Object); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// splitContent 按文档拆分content，每个文档转换成json，空文档忽略
+func splitContent(content string) ([]json.RawMessage, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(content)), 4096)
+	var docs []json.RawMessage
+	for {
+		var doc json.RawMessage
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(doc) == 0 || string(doc) == "null" {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("content为空")
+	}
+	return docs, nil
+}
+
+// RenderDetail 按format返回详情，yaml时转换成便于编辑的yaml，其它格式原样返回
+func RenderDetail(format string, obj interface{}) (interface{}, error) {
+	if format != FormatYAML {
+		return obj, nil
+	}
+	runtimeObj, ok := obj.(runtime.Object)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("%T不支持转换成yaml", obj))
+	}
+	return ToEditYAML(runtimeObj)
+}
+
+// ToEditYAML 将资源转换成便于编辑的yaml，去掉managedFields、status等由集群维护的字段，保留resourceVersion用于更新时的冲突检查
+func ToEditYAML(obj runtime.Object) (string, error) {
+	data, err := toCleanMap(obj)
+	if err != nil {
+		return "", err
+	}
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return "", errors.New("转换yaml失败, " + err.Error())
+	}
+	return string(out), nil
+}
+
+// toCleanMap 转换成map并去掉由集群维护的字段，typed对象补全apiVersion和kind
+func toCleanMap(obj runtime.Object) (map[string]interface{}, error) {
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.New("转换资源失败, " + err.Error())
+	}
+	u := &unstructured.Unstructured{Object: data}
+	if u.GetKind() == "" {
+		if gvks, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(gvks) > 0 {
+			u.SetAPIVersion(gvks[0].GroupVersion().String())
+			u.SetKind(gvks[0].Kind)
+		}
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(u.Object, "metadata", "uid")
+	unstructured.RemoveNestedField(u.Object, "metadata", "generation")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "metadata", "selfLink")
+	return u.Object, nil
+}
\ No newline at end of file
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var CRD crd
+
+type crd struct{}
+
+// CRDsResp 定义CRD列表的返回内容
+type CRDsResp struct {
+	Total int        `json:"total"`
+	Items []*CRDInfo `json:"items"`
+	PageInfo
+}
+
+// CRDDetail CRD详情，包含各版本的schema及打印列
+type CRDDetail struct {
+	Name       string        `json:"name"`
+	Group      string        `json:"group"`
+	Kind       string        `json:"kind"`
+	Plural     string        `json:"plural"`
+	Singular   string        `json:"singular"`
+	ShortNames []string      `json:"short_names"`
+	Categories []string      `json:"categories"`
+	Scope      string        `json:"scope"`
+	Versions   []*CRDVersion `json:"versions"`
+}
+
+// CRDVersion CRD的单个版本，Storage为etcd中存储使用的版本
+type CRDVersion struct {
+	Name           string                 `json:"name"`
+	Served         bool                   `json:"served"`
+	Storage        bool                   `json:"storage"`
+	Deprecated     bool                   `json:"deprecated"`
+	Schema         map[string]interface{} `json:"schema"` // openAPIV3Schema
+	PrinterColumns []*PrinterColumn       `json:"printer_columns"`
+}
+
+// PrinterColumn kubectl get时额外显示的列，JSONPath为取值路径
+type PrinterColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format"`
+	Description string `json:"description"`
+	Priority    int64  `json:"priority"`
+	JSONPath    string `json:"json_path"`
+}
+
+// CustomResourcesResp 定义自定义资源列表的返回内容，Columns为打印列，Items中的Cells与Columns一一对应
+type CustomResourcesResp struct {
+	Total   int                   `json:"total"`
+	Columns []*PrinterColumn      `json:"columns"`
+	Items   []*CustomResourceItem `json:"items"`
+	PageInfo
+}
+
+// CustomResourceItem 单个自定义资源及其打印列的值，取不到值时为nil
+type CustomResourceItem struct {
+	Object unstructured.Unstructured `json:"object"`
+	Cells  []interface{}             `json:"cells"`
+}
+
+// GetCRDs 获取CRD列表，支持过滤、排序、分页
+func (c *crd) GetCRDs(cc *ClusterClient, query *DataSelectQuery) (crdsResp *CRDsResp, err error) {
+	mapping, err := c.crdMapping(cc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Generic.GetResources(cc, mapping, "", query)
+	if err != nil {
+		return nil, err
+	}
+	crdsResp = &CRDsResp{Total: data.Total, Items: make([]*CRDInfo, 0, len(data.Items)), PageInfo: data.PageInfo}
+	for i := range data.Items {
+		crdsResp.Items = append(crdsResp.Items, toCRDInfo(&data.Items[i]))
+	}
+	return crdsResp, nil
+}
+
+// GetCRDDetail 获取CRD详情，包含各版本的schema及打印列
+func (c *crd) GetCRDDetail(cc *ClusterClient, name string) (detail *CRDDetail, err error) {
+	obj, err := c.getCRD(cc, name)
+	if err != nil {
+		return nil, err
+	}
+	info := toCRDInfo(obj)
+	detail = &CRDDetail{
+		Name:   info.Name,
+		Group:  info.Group,
+		Kind:   info.Kind,
+		Plural: info.Plural,
+		Scope:  info.Scope,
+	}
+	detail.Singular, _, _ = unstructured.NestedString(obj.Object, "spec", "names", "singular")
+	detail.ShortNames, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "names", "shortNames")
+	detail.Categories, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "names", "categories")
+	detail.Versions = crdVersions(obj)
+	return detail, nil
+}
+
+// GetCustomResources 获取CRD对应的自定义资源列表，namespace为空时获取所有namespace，支持过滤、排序、分页
+func (c *crd) GetCustomResources(cc *ClusterClient, crdName, version, namespace string, query *DataSelectQuery) (resp *CustomResourcesResp, err error) {
+	mapping, columns, err := c.Mapping(cc, crdName, version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Generic.GetResources(cc, mapping, namespace, query)
+	if err != nil {
+		return nil, err
+	}
+	resp = &CustomResourcesResp{
+		Total:    data.Total,
+		Columns:  columns,
+		Items:    make([]*CustomResourceItem, 0, len(data.Items)),
+		PageInfo: data.PageInfo,
+	}
+	for _, item := range data.Items {
+		resp.Items = append(resp.Items, &CustomResourceItem{Object: item, Cells: printerCells(item, columns)})
+	}
+	return resp, nil
+}
+
+// Mapping 根据CRD名称获取自定义资源的gvr及打印列，version为空时使用存储版本
+func (c *crd) Mapping(cc *ClusterClient, crdName, version string) (*ResourceMapping, []*PrinterColumn, error) {
+	obj, err := c.getCRD(cc, crdName)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := toCRDInfo(obj)
+	var selected *CRDVersion
+	for _, v := range crdVersions(obj) {
+		if (version == "" && v.Storage) || (version != "" && v.Name == version) {
+			selected = v
+			break
+		}
+	}
+	if selected == nil || !selected.Served {
+		return nil, nil, fmt.Errorf("%w, 集群: %s, CRD%s没有提供版本%s", ErrNotSupported, cc.Name, crdName, version)
+	}
+	mapping := &ResourceMapping{
+		GVR:        schema.GroupVersionResource{Group: info.Group, Version: selected.Name, Resource: info.Plural},
+		Kind:       info.Kind,
+		Namespaced: info.Scope == "Namespaced",
+	}
+	return mapping, selected.PrinterColumns, nil
+}
+
+// getCRD 获取CRD，集群未安装时返回ErrNotSupported
+func (c *crd) getCRD(cc *ClusterClient, name string) (*unstructured.Unstructured, error) {
+	if err := Capability.CheckResource(cc, CRDGVR); err != nil {
+		return nil, err
+	}
+	obj, err := cc.Dynamic.Resource(CRDGVR).Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w, 集群: %s, 未安装CRD: %s", ErrNotSupported, cc.Name, name)
+	}
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取CRD详情失败, %v", err.Error()))
+		return nil, newAPIError("获取CRD详情失败", err)
+	}
+	return obj, nil
+}
+
+// crdMapping CRD本身的资源信息
+func (c *crd) crdMapping(cc *ClusterClient) (*ResourceMapping, error) {
+	if err := Capability.CheckResource(cc, CRDGVR); err != nil {
+		return nil, err
+	}
+	return &ResourceMapping{GVR: CRDGVR, Kind: "CustomResourceDefinition"}, nil
+}
+
+// toCRDInfo 从CRD中取出概要信息，Versions只包含提供服务的版本
+func toCRDInfo(obj *unstructured.Unstructured) *CRDInfo {
+	info := &CRDInfo{Name: obj.GetName()}
+	info.Group, _, _ = unstructured.NestedString(obj.Object, "spec", "group")
+	info.Kind, _, _ = unstructured.NestedString(obj.Object, "spec", "names", "kind")
+	info.Plural, _, _ = unstructured.NestedString(obj.Object, "spec", "names", "plural")
+	info.Scope, _, _ = unstructured.NestedString(obj.Object, "spec", "scope")
+	for _, v := range crdVersions(obj) {
+		if v.Served {
+			info.Versions = append(info.Versions, v.Name)
+		}
+	}
+	return info
+}
+
+// crdVersions 解析CRD各版本的schema及打印列
+func crdVersions(obj *unstructured.Unstructured) []*CRDVersion {
+	items, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	versions := make([]*CRDVersion, 0, len(items))
+	for _, item := range items {
+		v, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		version := &CRDVersion{}
+		version.Name, _, _ = unstructured.NestedString(v, "name")
+		version.Served, _, _ = unstructured.NestedBool(v, "served")
+		version.Storage, _, _ = unstructured.NestedBool(v, "storage")
+		version.Deprecated, _, _ = unstructured.NestedBool(v, "deprecated")
+		version.Schema, _, _ = unstructured.NestedMap(v, "schema", "openAPIV3Schema")
+		columns, _, _ := unstructured.NestedSlice(v, "additionalPrinterColumns")
+		for _, col := range columns {
+			m, ok := col.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			column := &PrinterColumn{}
+			column.Name, _, _ = unstructured.NestedString(m, "name")
+			column.Type, _, _ = unstructured.NestedString(m, "type")
+			column.Format, _, _ = unstructured.NestedString(m, "format")
+			column.Description, _, _ = unstructured.NestedString(m, "description")
+			column.Priority, _, _ = unstructured.NestedInt64(m, "priority")
+			column.JSONPath, _, _ = unstructured.NestedString(m, "jsonPath")
+			version.PrinterColumns = append(version.PrinterColumns, column)
+		}
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// printerCells 按打印列的jsonPath取值，与kubectl get的显示一致
+func printerCells(obj unstructured.Unstructured, columns []*PrinterColumn) []interface{} {
+	cells := make([]interface{}, len(columns))
+	for i, column := range columns {
+		parser := jsonpath.New(column.Name).AllowMissingKeys(true)
+		if err := parser.Parse(fmt.Sprintf("{%s}", column.JSONPath)); err != nil {
+			continue
+		}
+		results, err := parser.FindResults(obj.Object)
+		if err != nil || len(results) == 0 || len(results[0]) == 0 {
+			continue
+		}
+		cells[i] = results[0][0].Interface()
+	}
+	return cells
+}
\ No newline at end of file
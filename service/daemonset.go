@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
@@ -22,6 +20,7 @@ type daemonSet struct{}
 type DaemonSetsResp struct {
 	Total int                `json:"total"`
 	Items []appsv1.DaemonSet `json:"items"`
+	PageInfo
 }
 
 // DaemonSetSNp 用于返回namespace中deployment的数量
@@ -45,23 +44,26 @@ type DaemonSetCreate struct {
 }
 
 // GetDaemonSets 获取DaemonSet列表
-func (d *daemonSet) GetDaemonSets(client *kubernetes.Clientset, filterName, namespace string, limit, page int) (daemonSetRest *DaemonSetsResp, err error) {
+func (d *daemonSet) GetDaemonSets(cc *ClusterClient, namespace string, query *DataSelectQuery) (daemonSetRest *DaemonSetsResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.AppsV1().DaemonSets(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取DaemonSet列表失败, %v", err.Error()))
+			return nil, newAPIError("获取DaemonSet列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(d.toCells(list.Items), list.ListMeta)
+		return &DaemonSetsResp{Total: total, Items: d.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取DaemonSetList类型的Daemonset列表
-	daemonSetList, err := client.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	daemonSetList, err := cc.Cache.ListDaemonSets(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取DaemonSet列表失败, %v", err.Error()))
-		return nil, errors.New("获取DaemonSet列表失败," + err.Error())
+		return nil, newAPIError("获取DaemonSet列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(daemonSetList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(daemonSetList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -72,18 +74,30 @@ func (d *daemonSet) GetDaemonSets(client *kubernetes.Clientset, filterName, name
 	daemonSets := d.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	daemonSetRest = &DaemonSetsResp{
-		Total: total,
-		Items: daemonSets,
+		Total:    total,
+		Items:    daemonSets,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return daemonSetRest, nil
 }
 
+// GetDaemonSetsAllClusters 跨集群获取DaemonSet列表
+func (d *daemonSet) GetDaemonSetsAllClusters(namespace string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		daemonSets, err := cc.Cache.ListDaemonSets(namespace)
+		if err != nil {
+			return nil, err
+		}
+		return d.toCells(daemonSets), nil
+	}, query)
+}
+
 // GetDaemonSetDetail 获取DaemonSet详情
 func (d *daemonSet) GetDaemonSetDetail(client *kubernetes.Clientset, daemonSetName, namespace string) (daemonset *appsv1.DaemonSet, err error) {
 	daemonset, err = client.AppsV1().DaemonSets(namespace).Get(context.TODO(), daemonSetName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取DaemonSet详情失败, %v", err.Error()))
-		return nil, errors.New("获取DaemonSet详情失败, " + err.Error())
+		return nil, newAPIError("获取DaemonSet详情失败", err)
 	}
 	return daemonset, nil
 }
@@ -93,23 +107,28 @@ func (d *daemonSet) DeleteDaemonSet(client *kubernetes.Clientset, daemonSetName,
 	err = client.AppsV1().DaemonSets(namespace).Delete(context.TODO(), daemonSetName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除DaemonSet失败, %v", err.Error()))
-		return errors.New("删除DaemonSet失败, " + err.Error())
+		return newAPIError("删除DaemonSet失败", err)
 	}
 	return nil
 }
 
+// RestartDaemonSet 重启DaemonSet，各节点上的Pod按更新策略逐个重建
+func (d *daemonSet) RestartDaemonSet(client *kubernetes.Clientset, daemonSetName, namespace string) (err error) {
+	return Workload.Restart(client, KindDaemonSet, namespace, daemonSetName)
+}
+
 // UpdateDaemonSet 更新DaemonSet
 func (d *daemonSet) UpdateDaemonSet(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var daemon = &appsv1.DaemonSet{}
-	err = json.Unmarshal([]byte(content), daemon)
+	err = decodeContent(content, daemon)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.AppsV1().DaemonSets(namespace).Update(context.TODO(), daemon, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新DaemonSet失败, %v", err.Error()))
-		return errors.New("更新DaemonSet失败, " + err.Error())
+		return newAPIError("更新DaemonSet失败", err)
 	}
 	return nil
 }
@@ -200,31 +219,31 @@ func (d *daemonSet) CreateDaemonSet(client *kubernetes.Clientset, data *DaemonSe
 	// 调用sdk创建deployment
 	if _, err = client.AppsV1().DaemonSets(data.Namespace).Create(context.TODO(), daemonset, metav1.CreateOptions{}); err != nil {
 		zap.L().Error(fmt.Sprintf("创建DaemonSet失败, %v", err.Error()))
-		return errors.New("创建DaemonSet失败," + err.Error())
+		return newAPIError("创建DaemonSet失败", err)
 	}
 
 	return nil
 }
 
 // GetDaemonSetNumPerNp 获取每个Namespace的DaemonSet的数量
-func (d *daemonSet) GetDaemonSetNumPerNp(client *kubernetes.Clientset) (DaemonSetSNps []*DaemonSetSNp, err error) {
+func (d *daemonSet) GetDaemonSetNumPerNp(cc *ClusterClient) (DaemonSetSNps []*DaemonSetSNp, err error) {
 	// 获取Namespace列表
-	namespaceList, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := cc.Cache.ListNamespaces()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Namespace列表失败, %v", err.Error()))
-		return nil, errors.New("获取Namespace列表失败, " + err.Error())
+		return nil, newAPIError("获取Namespace列表失败", err)
 	}
-	for _, namespace := range namespaceList.Items {
+	for _, namespace := range namespaceList {
 		// 获取Deployment列表
-		daemonSetList, err := client.AppsV1().DaemonSets(namespace.Name).List(context.TODO(), metav1.ListOptions{})
+		daemonSetList, err := cc.Cache.ListDaemonSets(namespace.Name)
 		if err != nil {
 			zap.L().Error(fmt.Sprintf("获取DaemonSet列表失败, %v", err.Error()))
-			return nil, errors.New("获取DaemonSet列表失败, " + err.Error())
+			return nil, newAPIError("获取DaemonSet列表失败", err)
 		}
 		// 组装数据
 		daemonNp := &DaemonSetSNp{
 			Namespace:    namespace.Name,
-			DaemonSetNum: len(daemonSetList.Items),
+			DaemonSetNum: len(daemonSetList),
 		}
 		// 添加数据到podsNps中
 		DaemonSetSNps = append(DaemonSetSNps, daemonNp)
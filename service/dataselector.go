@@ -1,10 +1,17 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	nwv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -19,21 +26,192 @@ type dataSelector struct {
 type DataCell interface {
 	GetCreation() time.Time
 	GetName() string
+	GetNamespace() string
+	GetLabels() map[string]string
+	// GetFields 字段选择器可以使用的字段，与apiserver支持的fieldSelector字段保持一致
+	GetFields() fields.Set
+	// GetStatus 状态过滤使用的状态，如pod的phase、deployment是否可用，没有状态的资源返回空
+	GetStatus() string
 }
 
-// DataSelectQuery 定义过滤和分页的结构体，过滤：Name，分页：Limit和Page
+// restartsCell 可以按重启次数排序的资源
+type restartsCell interface {
+	GetRestarts() int
+}
+
+// replicasCell 可以按副本数排序的资源
+type replicasCell interface {
+	GetReplicas() int
+}
+
+// DataSelectQuery 定义过滤、排序和分页的结构体，各项为nil时不做处理
 type DataSelectQuery struct {
 	Filter   *FilterQuery
+	Sort     *SortQuery
 	Paginate *PaginateQuery
 }
 
+// FilterQuery 过滤条件，各条件同时满足才返回，Name为名称包含匹配
 type FilterQuery struct {
-	Name string
+	Name   string
+	Label  labels.Selector
+	Field  fields.Selector
+	Status string
 }
 
+// SortQuery 排序条件，按Fields的顺序依次比较，为空时按创建时间倒序
+type SortQuery struct {
+	Fields []SortField
+}
+
+// SortField 单个排序字段，Desc为true时倒序
+type SortField struct {
+	Key  string
+	Desc bool
+}
+
+// PaginateQuery 分页条件，Mode为continue时由apiserver分页，Continue为上一页返回的token
 type PaginateQuery struct {
-	Limit int
-	Page  int
+	Limit    int
+	Page     int
+	Mode     string
+	Continue string
+}
+
+// 分页方式，page为获取全量列表后在内存中分页，可以得到过滤后的总数，适合数据量不大的列表
+// continue为apiserver分页，每次只获取limit条数据，适合pod数量巨大的namespace
+const (
+	PaginateModePage     = "page"
+	PaginateModeContinue = "continue"
+)
+
+// PageInfo 列表返回的分页信息，Mode为实际使用的分页方式
+// continue模式下Continue为空表示没有更多数据，Remaining为apiserver估算的剩余数量，设置了选择器时为空
+type PageInfo struct {
+	Mode      string `json:"mode"`
+	Continue  string `json:"continue,omitempty"`
+	Remaining *int64 `json:"remaining,omitempty"`
+}
+
+// 支持的排序字段，age为资源的存在时长，升序时新创建的资源在前
+const (
+	SortByName      = "name"
+	SortByNamespace = "namespace"
+	SortByAge       = "age"
+	SortByStatus    = "status"
+	SortByRestarts  = "restarts"
+	SortByReplicas  = "replicas"
+	SortByNode      = "node"
+)
+
+var sortKeys = map[string]bool{
+	SortByName:      true,
+	SortByNamespace: true,
+	SortByAge:       true,
+	SortByStatus:    true,
+	SortByRestarts:  true,
+	SortByReplicas:  true,
+	SortByNode:      true,
+}
+
+// NewFilterQuery 解析过滤条件，labelSelector、fieldSelector的格式与kubectl的-l、--field-selector一致
+func NewFilterQuery(name, labelSelector, fieldSelector, status string) (*FilterQuery, error) {
+	filter := &FilterQuery{Name: name, Status: status}
+	if labelSelector != "" {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			return nil, errors.New("labelSelector格式错误, " + err.Error())
+		}
+		filter.Label = selector
+	}
+	if fieldSelector != "" {
+		selector, err := fields.ParseSelector(fieldSelector)
+		if err != nil {
+			return nil, errors.New("fieldSelector格式错误, " + err.Error())
+		}
+		filter.Field = selector
+	}
+	return filter, nil
+}
+
+// NewSortQuery 解析排序条件，格式为 字段[:asc|desc]，多个字段用逗号分隔，如 restarts:desc,name
+func NewSortQuery(sortBy string) (*SortQuery, error) {
+	query := &SortQuery{}
+	for _, item := range strings.Split(sortBy, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, order, _ := strings.Cut(item, ":")
+		if !sortKeys[key] {
+			return nil, errors.New(fmt.Sprintf("不支持的排序字段: %s", key))
+		}
+		switch order {
+		case "", "asc":
+			query.Fields = append(query.Fields, SortField{Key: key})
+		case "desc":
+			query.Fields = append(query.Fields, SortField{Key: key, Desc: true})
+		default:
+			return nil, errors.New(fmt.Sprintf("不支持的排序方式: %s, 可选asc、desc", order))
+		}
+	}
+	return query, nil
+}
+
+// NewPaginateQuery 解析分页条件，mode为空时使用page模式，continue模式必须指定limit
+func NewPaginateQuery(mode string, limit, page int, continueToken string) (*PaginateQuery, error) {
+	switch mode {
+	case "", PaginateModePage:
+		return &PaginateQuery{Limit: limit, Page: page, Mode: PaginateModePage}, nil
+	case PaginateModeContinue:
+		if limit <= 0 {
+			return nil, errors.New("continue分页必须指定limit")
+		}
+		return &PaginateQuery{Limit: limit, Mode: PaginateModeContinue, Continue: continueToken}, nil
+	}
+	return nil, errors.New(fmt.Sprintf("不支持的分页方式: %s, 可选page、continue", mode))
+}
+
+// ServerSide 是否由apiserver分页
+func (q *DataSelectQuery) ServerSide() bool {
+	return q.Paginate != nil && q.Paginate.Mode == PaginateModeContinue
+}
+
+// ListOptions apiserver分页时的请求参数，标签及字段选择器交给apiserver过滤
+func (q *DataSelectQuery) ListOptions() metav1.ListOptions {
+	opts := metav1.ListOptions{
+		Limit:    int64(q.Paginate.Limit),
+		Continue: q.Paginate.Continue,
+	}
+	if q.Filter != nil && q.Filter.Label != nil {
+		opts.LabelSelector = q.Filter.Label.String()
+	}
+	if q.Filter != nil && q.Filter.Field != nil {
+		opts.FieldSelector = q.Filter.Field.String()
+	}
+	return opts
+}
+
+// selectPage 处理apiserver返回的一页数据，名称和状态条件只能在当前页内过滤，过滤后的数量可能小于limit
+func (q *DataSelectQuery) selectPage(cells []DataCell, meta metav1.ListMeta) ([]DataCell, int, PageInfo) {
+	selectableData := &dataSelector{
+		GenericDataList: cells,
+		DataSelect:      &DataSelectQuery{},
+	}
+	if q.Filter != nil {
+		selectableData.DataSelect.Filter = &FilterQuery{Name: q.Filter.Name, Status: q.Filter.Status}
+	}
+	data := selectableData.Filter()
+	// 没有剩余数量时total只包含当前页
+	total := len(cells)
+	if meta.RemainingItemCount != nil {
+		total += int(*meta.RemainingItemCount)
+	}
+	return data.GenericDataList, total, PageInfo{
+		Mode:      PaginateModeContinue,
+		Continue:  meta.Continue,
+		Remaining: meta.RemainingItemCount,
+	}
 }
 
 // 实现自定义结构的排序，需要重写Len、Swap、Less方法
@@ -47,12 +225,25 @@ func (d *dataSelector) Swap(i, j int) {
 	d.GenericDataList[i], d.GenericDataList[j] = d.GenericDataList[j], d.GenericDataList[i]
 }
 
-// Less 用于排序，比较大小
+// Less 用于排序，比较大小，依次比较各排序字段，全部相等时按名称排序，保证分页结果稳定
 func (d *dataSelector) Less(i, j int) bool {
-	a := d.GenericDataList[i].GetCreation()
-	b := d.GenericDataList[j].GetCreation()
-	return b.Before(a)
-	//return d.GenericDataList[i].GetCreation().Before(d.GenericDataList[j].GetCreation())
+	a := d.GenericDataList[i]
+	b := d.GenericDataList[j]
+	sortFields := []SortField{{Key: SortByAge}}
+	if d.DataSelect.Sort != nil && len(d.DataSelect.Sort.Fields) > 0 {
+		sortFields = d.DataSelect.Sort.Fields
+	}
+	for _, field := range sortFields {
+		result := compareCell(a, b, field.Key)
+		if result == 0 {
+			continue
+		}
+		if field.Desc {
+			return result > 0
+		}
+		return result < 0
+	}
+	return a.GetName() < b.GetName()
 }
 
 // Sort 重写以上三个方法，使用sort.Sort进行排序
@@ -61,25 +252,28 @@ func (d *dataSelector) Sort() *dataSelector {
 	return d
 }
 
-// Filter 方法用于过滤数据，比较数据的Name属性，若包含，则返回，
+// Filter 方法用于过滤数据，名称、标签、字段、状态条件同时满足时返回
 func (d *dataSelector) Filter() *dataSelector {
-	// 判断参数name是否为空，若为空，则返回所有数据
-	if d.DataSelect.Filter.Name == "" {
+	filter := d.DataSelect.Filter
+	// 没有过滤条件时返回所有数据
+	if filter == nil || (filter.Name == "" && filter.Label == nil && filter.Field == nil && filter.Status == "") {
 		return d
 	}
-	//	如果不为空，则按照入参Name进行过滤，然后返回
 	filtered := []DataCell{}
 	for _, value := range d.GenericDataList {
-		// 定义是否匹配的标签变量，默认是匹配
-		match := true
-		objName := value.GetName()
-		if !strings.Contains(objName, d.DataSelect.Filter.Name) {
-			match = false
+		if filter.Name != "" && !strings.Contains(value.GetName(), filter.Name) {
+			continue
+		}
+		if filter.Label != nil && !filter.Label.Matches(labels.Set(value.GetLabels())) {
 			continue
 		}
-		if match {
-			filtered = append(filtered, value)
+		if filter.Field != nil && !filter.Field.Matches(value.GetFields()) {
+			continue
 		}
+		if filter.Status != "" && !strings.EqualFold(value.GetStatus(), filter.Status) {
+			continue
+		}
+		filtered = append(filtered, value)
 	}
 	d.GenericDataList = filtered
 	return d
@@ -87,6 +281,9 @@ func (d *dataSelector) Filter() *dataSelector {
 
 // Paginate 方法用于分页，获取分页数据, 根据Limit和Page的传惨，取一定范围内的数据，返回
 func (d *dataSelector) Paginate() *dataSelector {
+	if d.DataSelect.Paginate == nil {
+		return d
+	}
 	// 根据Limit和Page的入参，定义快捷变量
 	limit := d.DataSelect.Paginate.Limit
 	page := d.DataSelect.Paginate.Page
@@ -98,7 +295,10 @@ func (d *dataSelector) Paginate() *dataSelector {
 	//举例：25个元素的数组，limit是10，page是3，startIndex是20，endIndex是30（实际上endIndex是 24）
 	startIndex := limit * (page - 1)
 	endIndex := limit * page
-	// 处理endIndex
+	// 处理endIndex，页码超出范围时返回空列表
+	if startIndex > len(d.GenericDataList) {
+		startIndex = len(d.GenericDataList)
+	}
 	if endIndex > len(d.GenericDataList) {
 		endIndex = len(d.GenericDataList)
 	}
@@ -107,7 +307,84 @@ func (d *dataSelector) Paginate() *dataSelector {
 	return d
 }
 
-// podCell 定义podCell 重写GetCreation和GetName方法后，可以进行数据交换
+// compareCell 按排序字段比较两个资源，a小于b返回负数，相等返回0
+func compareCell(a, b DataCell, key string) int {
+	switch key {
+	case SortByName:
+		return strings.Compare(a.GetName(), b.GetName())
+	case SortByNamespace:
+		return strings.Compare(a.GetNamespace(), b.GetNamespace())
+	case SortByAge:
+		// 创建时间越晚，存在时长越短
+		ta, tb := a.GetCreation(), b.GetCreation()
+		if ta.After(tb) {
+			return -1
+		}
+		if ta.Before(tb) {
+			return 1
+		}
+		return 0
+	case SortByStatus:
+		return strings.Compare(a.GetStatus(), b.GetStatus())
+	case SortByNode:
+		return strings.Compare(a.GetFields()["spec.nodeName"], b.GetFields()["spec.nodeName"])
+	case SortByRestarts:
+		return cellRestarts(a) - cellRestarts(b)
+	case SortByReplicas:
+		return cellReplicas(a) - cellReplicas(b)
+	}
+	return 0
+}
+
+// cellRestarts 获取资源的重启次数，不支持的资源视为0
+func cellRestarts(cell DataCell) int {
+	if c, ok := unwrapCell(cell).(restartsCell); ok {
+		return c.GetRestarts()
+	}
+	return 0
+}
+
+// cellReplicas 获取资源的期望副本数，不支持的资源视为0
+func cellReplicas(cell DataCell) int {
+	if c, ok := unwrapCell(cell).(replicasCell); ok {
+		return c.GetReplicas()
+	}
+	return 0
+}
+
+// unwrapCell 跨集群列表中的资源包装了集群名，取出原始资源后才能判断可选接口
+func unwrapCell(cell DataCell) DataCell {
+	if c, ok := cell.(clusterCell); ok {
+		return c.DataCell
+	}
+	return cell
+}
+
+// metaFields 所有资源都支持的字段
+func metaFields(meta metav1.ObjectMeta) fields.Set {
+	return fields.Set{
+		"metadata.name":      meta.Name,
+		"metadata.namespace": meta.Namespace,
+	}
+}
+
+// replicasStatus 根据期望及可用副本数判断工作负载是否可用
+func replicasStatus(desired, available int32) string {
+	if available >= desired {
+		return "Available"
+	}
+	return "Unavailable"
+}
+
+// desiredReplicas spec.replicas未设置时默认为1
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// podCell 定义podCell 重写DataCell的方法后，可以进行数据交换
 type podCell corev1.Pod
 
 func (p podCell) GetCreation() time.Time {
@@ -118,7 +395,40 @@ func (p podCell) GetName() string {
 	return p.Name
 }
 
-// deploymentCell 定义deploymentCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (p podCell) GetNamespace() string {
+	return p.Namespace
+}
+
+func (p podCell) GetLabels() map[string]string {
+	return p.Labels
+}
+
+func (p podCell) GetFields() fields.Set {
+	set := metaFields(p.ObjectMeta)
+	set["spec.nodeName"] = p.Spec.NodeName
+	set["spec.restartPolicy"] = string(p.Spec.RestartPolicy)
+	set["spec.schedulerName"] = p.Spec.SchedulerName
+	set["spec.serviceAccountName"] = p.Spec.ServiceAccountName
+	set["status.phase"] = string(p.Status.Phase)
+	set["status.podIP"] = p.Status.PodIP
+	set["status.nominatedNodeName"] = p.Status.NominatedNodeName
+	return set
+}
+
+// GetStatus 与Pod摘要的状态一致，可以按CrashLoopBackOff、Init:0/1、Terminating等过滤
+func (p podCell) GetStatus() string {
+	pod := corev1.Pod(p)
+	status, _, _ := podStatus(&pod)
+	return status
+}
+
+func (p podCell) GetRestarts() int {
+	pod := corev1.Pod(p)
+	_, _, restarts := podStatus(&pod)
+	return restarts
+}
+
+// deploymentCell 定义deploymentCell 重写DataCell的方法后，可以进行数据交换
 type deploymentCell appsv1.Deployment
 
 func (d deploymentCell) GetCreation() time.Time {
@@ -129,7 +439,27 @@ func (d deploymentCell) GetName() string {
 	return d.Name
 }
 
-// daemonSetCell 定义DaemonSetCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (d deploymentCell) GetNamespace() string {
+	return d.Namespace
+}
+
+func (d deploymentCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d deploymentCell) GetFields() fields.Set {
+	return metaFields(d.ObjectMeta)
+}
+
+func (d deploymentCell) GetStatus() string {
+	return replicasStatus(desiredReplicas(d.Spec.Replicas), d.Status.AvailableReplicas)
+}
+
+func (d deploymentCell) GetReplicas() int {
+	return int(desiredReplicas(d.Spec.Replicas))
+}
+
+// daemonSetCell 定义daemonSetCell 重写DataCell的方法后，可以进行数据交换
 type daemonSetCell appsv1.DaemonSet
 
 func (d daemonSetCell) GetCreation() time.Time {
@@ -140,7 +470,27 @@ func (d daemonSetCell) GetName() string {
 	return d.Name
 }
 
-// statefulSetCell 定义StatefulSetCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (d daemonSetCell) GetNamespace() string {
+	return d.Namespace
+}
+
+func (d daemonSetCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d daemonSetCell) GetFields() fields.Set {
+	return metaFields(d.ObjectMeta)
+}
+
+func (d daemonSetCell) GetStatus() string {
+	return replicasStatus(d.Status.DesiredNumberScheduled, d.Status.NumberAvailable)
+}
+
+func (d daemonSetCell) GetReplicas() int {
+	return int(d.Status.DesiredNumberScheduled)
+}
+
+// statefulSetCell 定义statefulSetCell 重写DataCell的方法后，可以进行数据交换
 type statefulSetCell appsv1.StatefulSet
 
 func (s statefulSetCell) GetCreation() time.Time {
@@ -151,7 +501,27 @@ func (s statefulSetCell) GetName() string {
 	return s.Name
 }
 
-// nodeCell 定义nodeCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (s statefulSetCell) GetNamespace() string {
+	return s.Namespace
+}
+
+func (s statefulSetCell) GetLabels() map[string]string {
+	return s.Labels
+}
+
+func (s statefulSetCell) GetFields() fields.Set {
+	return metaFields(s.ObjectMeta)
+}
+
+func (s statefulSetCell) GetStatus() string {
+	return replicasStatus(desiredReplicas(s.Spec.Replicas), s.Status.AvailableReplicas)
+}
+
+func (s statefulSetCell) GetReplicas() int {
+	return int(desiredReplicas(s.Spec.Replicas))
+}
+
+// nodeCell 定义nodeCell 重写DataCell的方法后，可以进行数据交换
 type nodeCell corev1.Node
 
 func (n nodeCell) GetCreation() time.Time {
@@ -162,7 +532,30 @@ func (n nodeCell) GetName() string {
 	return n.Name
 }
 
-// namespaceCell 定义namespaceCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (n nodeCell) GetNamespace() string {
+	return n.Namespace
+}
+
+func (n nodeCell) GetLabels() map[string]string {
+	return n.Labels
+}
+
+func (n nodeCell) GetFields() fields.Set {
+	set := metaFields(n.ObjectMeta)
+	set["spec.unschedulable"] = strconv.FormatBool(n.Spec.Unschedulable)
+	return set
+}
+
+func (n nodeCell) GetStatus() string {
+	for _, condition := range n.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			return "Ready"
+		}
+	}
+	return "NotReady"
+}
+
+// namespaceCell 定义namespaceCell 重写DataCell的方法后，可以进行数据交换
 type namespaceCell corev1.Namespace
 
 func (n namespaceCell) GetCreation() time.Time {
@@ -173,7 +566,25 @@ func (n namespaceCell) GetName() string {
 	return n.Name
 }
 
-// pvCell 定义pvCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (n namespaceCell) GetNamespace() string {
+	return n.Namespace
+}
+
+func (n namespaceCell) GetLabels() map[string]string {
+	return n.Labels
+}
+
+func (n namespaceCell) GetFields() fields.Set {
+	set := metaFields(n.ObjectMeta)
+	set["status.phase"] = string(n.Status.Phase)
+	return set
+}
+
+func (n namespaceCell) GetStatus() string {
+	return string(n.Status.Phase)
+}
+
+// pvCell 定义pvCell 重写DataCell的方法后，可以进行数据交换
 type pvCell corev1.PersistentVolume
 
 func (p pvCell) GetCreation() time.Time {
@@ -184,7 +595,26 @@ func (p pvCell) GetName() string {
 	return p.Name
 }
 
-// serviceCell 定义serviceCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (p pvCell) GetNamespace() string {
+	return p.Namespace
+}
+
+func (p pvCell) GetLabels() map[string]string {
+	return p.Labels
+}
+
+func (p pvCell) GetFields() fields.Set {
+	set := metaFields(p.ObjectMeta)
+	set["status.phase"] = string(p.Status.Phase)
+	set["spec.storageClassName"] = p.Spec.StorageClassName
+	return set
+}
+
+func (p pvCell) GetStatus() string {
+	return string(p.Status.Phase)
+}
+
+// serviceCell 定义serviceCell 重写DataCell的方法后，可以进行数据交换
 type serviceCell corev1.Service
 
 func (s serviceCell) GetCreation() time.Time {
@@ -195,7 +625,26 @@ func (s serviceCell) GetName() string {
 	return s.Name
 }
 
-// serviceCell 定义serviceCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (s serviceCell) GetNamespace() string {
+	return s.Namespace
+}
+
+func (s serviceCell) GetLabels() map[string]string {
+	return s.Labels
+}
+
+func (s serviceCell) GetFields() fields.Set {
+	set := metaFields(s.ObjectMeta)
+	set["spec.type"] = string(s.Spec.Type)
+	set["spec.clusterIP"] = s.Spec.ClusterIP
+	return set
+}
+
+func (s serviceCell) GetStatus() string {
+	return ""
+}
+
+// ingressCell 定义ingressCell 重写DataCell的方法后，可以进行数据交换
 type ingressCell nwv1.Ingress
 
 func (i ingressCell) GetCreation() time.Time {
@@ -206,7 +655,23 @@ func (i ingressCell) GetName() string {
 	return i.Name
 }
 
-// configmapCell 定义configmapCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (i ingressCell) GetNamespace() string {
+	return i.Namespace
+}
+
+func (i ingressCell) GetLabels() map[string]string {
+	return i.Labels
+}
+
+func (i ingressCell) GetFields() fields.Set {
+	return metaFields(i.ObjectMeta)
+}
+
+func (i ingressCell) GetStatus() string {
+	return ""
+}
+
+// configmapCell 定义configmapCell 重写DataCell的方法后，可以进行数据交换
 type configmapCell corev1.ConfigMap
 
 func (c configmapCell) GetCreation() time.Time {
@@ -217,7 +682,23 @@ func (c configmapCell) GetName() string {
 	return c.Name
 }
 
-// secretCell 定义secretCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (c configmapCell) GetNamespace() string {
+	return c.Namespace
+}
+
+func (c configmapCell) GetLabels() map[string]string {
+	return c.Labels
+}
+
+func (c configmapCell) GetFields() fields.Set {
+	return metaFields(c.ObjectMeta)
+}
+
+func (c configmapCell) GetStatus() string {
+	return ""
+}
+
+// secretCell 定义secretCell 重写DataCell的方法后，可以进行数据交换
 type secretCell corev1.Secret
 
 func (s secretCell) GetCreation() time.Time {
@@ -228,7 +709,25 @@ func (s secretCell) GetName() string {
 	return s.Name
 }
 
-// pvcCell 定义pvcCell 重写GetCreation和GetName方法后，可以进行数据交换
+func (s secretCell) GetNamespace() string {
+	return s.Namespace
+}
+
+func (s secretCell) GetLabels() map[string]string {
+	return s.Labels
+}
+
+func (s secretCell) GetFields() fields.Set {
+	set := metaFields(s.ObjectMeta)
+	set["type"] = string(s.Type)
+	return set
+}
+
+func (s secretCell) GetStatus() string {
+	return ""
+}
+
+// pvcCell 定义pvcCell 重写DataCell的方法后，可以进行数据交换
 type pvcCell corev1.PersistentVolumeClaim
 
 func (p pvcCell) GetCreation() time.Time {
@@ -237,4 +736,71 @@ func (p pvcCell) GetCreation() time.Time {
 
 func (p pvcCell) GetName() string {
 	return p.Name
+}
+
+func (p pvcCell) GetNamespace() string {
+	return p.Namespace
+}
+
+func (p pvcCell) GetLabels() map[string]string {
+	return p.Labels
+}
+
+func (p pvcCell) GetFields() fields.Set {
+	set := metaFields(p.ObjectMeta)
+	set["status.phase"] = string(p.Status.Phase)
+	set["spec.volumeName"] = p.Spec.VolumeName
+	return set
+}
+
+func (p pvcCell) GetStatus() string {
+	return string(p.Status.Phase)
+}
+
+// unstructuredCell 定义unstructuredCell 通用资源使用，字段从Object中按路径读取
+type unstructuredCell unstructured.Unstructured
+
+func (u unstructuredCell) GetCreation() time.Time {
+	obj := unstructured.Unstructured(u)
+	return obj.GetCreationTimestamp().Time
+}
+
+func (u unstructuredCell) GetName() string {
+	obj := unstructured.Unstructured(u)
+	return obj.GetName()
+}
+
+func (u unstructuredCell) GetNamespace() string {
+	obj := unstructured.Unstructured(u)
+	return obj.GetNamespace()
+}
+
+func (u unstructuredCell) GetLabels() map[string]string {
+	obj := unstructured.Unstructured(u)
+	return obj.GetLabels()
+}
+
+func (u unstructuredCell) GetFields() fields.Set {
+	set := fields.Set{
+		"metadata.name":      u.GetName(),
+		"metadata.namespace": u.GetNamespace(),
+	}
+	if nodeName, ok, _ := unstructured.NestedString(u.Object, "spec", "nodeName"); ok {
+		set["spec.nodeName"] = nodeName
+	}
+	if phase, ok, _ := unstructured.NestedString(u.Object, "status", "phase"); ok {
+		set["status.phase"] = phase
+	}
+	return set
+}
+
+// GetStatus 大部分资源的状态在status.phase中，没有时返回空
+func (u unstructuredCell) GetStatus() string {
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	return phase
+}
+
+func (u unstructuredCell) GetReplicas() int {
+	replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	return int(replicas)
 }
\ No newline at end of file
@@ -0,0 +1,199 @@
+package service
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testPod(name, namespace, node string, phase corev1.PodPhase, restarts int32, age time.Duration, labels map[string]string) DataCell {
+	return podCell(corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(-age)),
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Phase:             phase,
+			ContainerStatuses: []corev1.ContainerStatus{{RestartCount: restarts}},
+		},
+	})
+}
+
+func testPods() []DataCell {
+	return []DataCell{
+		testPod("web-1", "default", "node-a", corev1.PodRunning, 3, time.Hour, map[string]string{"app": "web"}),
+		testPod("web-2", "default", "node-b", corev1.PodPending, 0, time.Minute, map[string]string{"app": "web"}),
+		testPod("db-1", "data", "node-a", corev1.PodRunning, 7, 2*time.Hour, map[string]string{"app": "db"}),
+		testPod("job-1", "default", "node-b", corev1.PodSucceeded, 0, 3*time.Hour, nil),
+	}
+}
+
+func cellNames(cells []DataCell) []string {
+	names := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		names = append(names, cell.GetName())
+	}
+	return names
+}
+
+func TestDataSelectorFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		nameFilter    string
+		labelSelector string
+		fieldSelector string
+		status        string
+		want          []string
+	}{
+		{name: "no filter", want: []string{"web-1", "web-2", "db-1", "job-1"}},
+		{name: "name contains", nameFilter: "web", want: []string{"web-1", "web-2"}},
+		{name: "label selector", labelSelector: "app=db", want: []string{"db-1"}},
+		{name: "label exists", labelSelector: "app", want: []string{"web-1", "web-2", "db-1"}},
+		{name: "field selector", fieldSelector: "spec.nodeName=node-b", want: []string{"web-2", "job-1"}},
+		{name: "status case insensitive", status: "running", want: []string{"web-1", "db-1"}},
+		{name: "combined", nameFilter: "web", fieldSelector: "status.phase=Running", want: []string{"web-1"}},
+		{name: "no match", nameFilter: "cache", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilterQuery(tt.nameFilter, tt.labelSelector, tt.fieldSelector, tt.status)
+			if err != nil {
+				t.Fatalf("NewFilterQuery() error = %v", err)
+			}
+			selector := &dataSelector{GenericDataList: testPods(), DataSelect: &DataSelectQuery{Filter: filter}}
+			if got := cellNames(selector.Filter().GenericDataList); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodCellStatus(t *testing.T) {
+	crash := podCell(corev1.Pod{
+		Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: "init"}}, Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{
+			Phase:                 corev1.PodRunning,
+			InitContainerStatuses: []corev1.ContainerStatus{{State: stateTerminated("Completed", 0), RestartCount: 2}},
+			ContainerStatuses:     []corev1.ContainerStatus{{State: stateWaiting("CrashLoopBackOff"), RestartCount: 5}},
+		},
+	})
+	if got := crash.GetStatus(); got != "CrashLoopBackOff" {
+		t.Errorf("GetStatus() = %v, want CrashLoopBackOff", got)
+	}
+	if got := crash.GetRestarts(); got != 5 {
+		t.Errorf("GetRestarts() = %v, want 5", got)
+	}
+	initializing := podCell(corev1.Pod{
+		Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: "init"}}, Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{
+			Phase:                 corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{{State: stateWaiting("ImagePullBackOff"), RestartCount: 1}},
+		},
+	})
+	if got := initializing.GetStatus(); got != "Init:ImagePullBackOff" {
+		t.Errorf("GetStatus() = %v, want Init:ImagePullBackOff", got)
+	}
+	if got := initializing.GetRestarts(); got != 1 {
+		t.Errorf("GetRestarts() = %v, want 1", got)
+	}
+	filter, err := NewFilterQuery("", "", "", "crashloopbackoff")
+	if err != nil {
+		t.Fatalf("NewFilterQuery() error = %v", err)
+	}
+	selector := &dataSelector{GenericDataList: []DataCell{crash, initializing}, DataSelect: &DataSelectQuery{Filter: filter}}
+	if got := len(selector.Filter().GenericDataList); got != 1 {
+		t.Errorf("Filter() returned %v pods, want 1", got)
+	}
+}
+
+func TestDataSelectorSort(t *testing.T) {
+	tests := []struct {
+		name   string
+		sortBy string
+		want   []string
+	}{
+		{name: "default newest first", want: []string{"web-2", "web-1", "db-1", "job-1"}},
+		{name: "name asc", sortBy: "name", want: []string{"db-1", "job-1", "web-1", "web-2"}},
+		{name: "age desc", sortBy: "age:desc", want: []string{"job-1", "db-1", "web-1", "web-2"}},
+		{name: "restarts desc then name", sortBy: "restarts:desc", want: []string{"db-1", "web-1", "job-1", "web-2"}},
+		{name: "namespace then node desc", sortBy: "namespace,node:desc", want: []string{"db-1", "job-1", "web-2", "web-1"}},
+		{name: "status", sortBy: "status", want: []string{"web-2", "db-1", "web-1", "job-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := NewSortQuery(tt.sortBy)
+			if err != nil {
+				t.Fatalf("NewSortQuery() error = %v", err)
+			}
+			selector := &dataSelector{GenericDataList: testPods(), DataSelect: &DataSelectQuery{Sort: query}}
+			if got := cellNames(selector.Sort().GenericDataList); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSortQueryInvalid(t *testing.T) {
+	for _, sortBy := range []string{"size", "name:up", "age,cpu"} {
+		if _, err := NewSortQuery(sortBy); err == nil {
+			t.Errorf("NewSortQuery(%q) expected error", sortBy)
+		}
+	}
+}
+
+func TestDataSelectorPaginate(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		page  int
+		want  []string
+	}{
+		{name: "first page", limit: 2, page: 1, want: []string{"web-1", "web-2"}},
+		{name: "last partial page", limit: 3, page: 2, want: []string{"job-1"}},
+		{name: "page out of range", limit: 2, page: 5, want: []string{}},
+		{name: "zero limit returns all", limit: 0, page: 1, want: []string{"web-1", "web-2", "db-1", "job-1"}},
+		{name: "zero page returns all", limit: 2, page: 0, want: []string{"web-1", "web-2", "db-1", "job-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := &dataSelector{
+				GenericDataList: testPods(),
+				DataSelect:      &DataSelectQuery{Paginate: &PaginateQuery{Limit: tt.limit, Page: tt.page, Mode: PaginateModePage}},
+			}
+			if got := cellNames(selector.Paginate().GenericDataList); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Paginate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPaginateQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		limit    int
+		wantMode string
+		wantErr  bool
+	}{
+		{name: "default page mode", mode: "", limit: 10, wantMode: PaginateModePage},
+		{name: "continue mode", mode: PaginateModeContinue, limit: 10, wantMode: PaginateModeContinue},
+		{name: "continue without limit", mode: PaginateModeContinue, wantErr: true},
+		{name: "unknown mode", mode: "cursor", limit: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := NewPaginateQuery(tt.mode, tt.limit, 1, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPaginateQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && query.Mode != tt.wantMode {
+				t.Errorf("NewPaginateQuery() mode = %v, want %v", query.Mode, tt.wantMode)
+			}
+		})
+	}
+}
\ No newline at end of file
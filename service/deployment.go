@@ -2,19 +2,15 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
-	"strconv"
-	"time"
+	"k8s.io/client-go/util/retry"
 )
 
 var Deployment deployment
@@ -25,6 +21,7 @@ type deployment struct{}
 type DeploymentsResp struct {
 	Total int                 `json:"total"`
 	Items []appsv1.Deployment `json:"items"`
+	PageInfo
 }
 
 // DeployCreate 定义DeployCreate结构体，用于创建deployment需要的参数属性的定义
@@ -49,23 +46,26 @@ type DeploySNp struct {
 }
 
 // GetDeployments 获取Deployment列表，支持过滤、排序、分页
-func (d *deployment) GetDeployments(client *kubernetes.Clientset, filterName, namespace string, limit, page int) (deploymentsRest *DeploymentsResp, err error) {
+func (d *deployment) GetDeployments(cc *ClusterClient, namespace string, query *DataSelectQuery) (deploymentsRest *DeploymentsResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.AppsV1().Deployments(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Deployment列表失败, %v", err.Error()))
+			return nil, newAPIError("获取Deployment列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(d.toCells(list.Items), list.ListMeta)
+		return &DeploymentsResp{Total: total, Items: d.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取DeploymentList类型的deployment列表
-	deploymentList, err := client.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	deploymentList, err := cc.Cache.ListDeployments(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Deployment列表失败, %v", err.Error()))
-		return nil, errors.New("获取Deployment列表失败," + err.Error())
+		return nil, newAPIError("获取Deployment列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(deploymentList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(deploymentList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -77,38 +77,53 @@ func (d *deployment) GetDeployments(client *kubernetes.Clientset, filterName, na
 
 	// 拼接返回数据
 	deploymentsRest = &DeploymentsResp{
-		Total: total,
-		Items: deployments,
+		Total:    total,
+		Items:    deployments,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return deploymentsRest, nil
 }
 
+// GetDeploymentsAllClusters 跨集群获取Deployment列表
+func (d *deployment) GetDeploymentsAllClusters(namespace string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		deployments, err := cc.Cache.ListDeployments(namespace)
+		if err != nil {
+			return nil, err
+		}
+		return d.toCells(deployments), nil
+	}, query)
+}
+
 // GetDeploymentDetail 获取Deployment详情
 func (d *deployment) GetDeploymentDetail(client *kubernetes.Clientset, deploymentName, namespace string) (deployment *appsv1.Deployment, err error) {
 	deployment, err = client.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Deployment详情失败, %v", err.Error()))
-		return nil, errors.New("获取Deployment详情失败, " + err.Error())
+		return nil, newAPIError("获取Deployment详情失败", err)
 	}
 	return deployment, nil
 }
 
 // SetDeploymentReplicas 设置Deployment副本数
 func (d *deployment) SetDeploymentReplicas(client *kubernetes.Clientset, deploymentName, namespace string, replicas int32) (replica int32, err error) {
-	//获取autoscalingv1.Scale类型的对象，能点出当前的副本数
-	scale, err := client.AppsV1().Deployments(namespace).GetScale(context.TODO(), deploymentName, metav1.GetOptions{})
-	if err != nil {
-		zap.L().Error(fmt.Sprintf("获取Deployment副本数失败, %v", err.Error()))
-		return 0, errors.New("获取Deployment副本数失败," + err.Error())
-	}
-	// 修改副本数
-	scale.Spec.Replicas = replicas
-	_, err = client.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), deploymentName, scale, metav1.UpdateOptions{})
+	// 只修改副本数，与其它修改互不影响，resourceVersion冲突时重新获取后重试
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		//获取autoscalingv1.Scale类型的对象，能点出当前的副本数
+		scale, err := client.AppsV1().Deployments(namespace).GetScale(context.TODO(), deploymentName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// 修改副本数
+		scale.Spec.Replicas = replicas
+		_, err = client.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), deploymentName, scale, metav1.UpdateOptions{})
+		return err
+	})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新Deployment副本数失败, %v", err.Error()))
-		return 0, errors.New("更新Deployment副本数失败," + err.Error())
+		return 0, newAPIError("更新Deployment副本数失败", err)
 	}
-	return scale.Spec.Replicas, nil
+	return replicas, nil
 }
 
 // CreateDeployment 创建Deployment，接收DeployCreate对象
@@ -206,7 +221,7 @@ func (d *deployment) CreateDeployment(client *kubernetes.Clientset, data *Deploy
 	_, err = client.AppsV1().Deployments(data.Namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("创建Deployment失败, %v", err.Error()))
-		return errors.New("创建Deployment失败," + err.Error())
+		return newAPIError("创建Deployment失败", err)
 	}
 	return nil
 }
@@ -216,97 +231,51 @@ func (d *deployment) DeleteDeployment(client *kubernetes.Clientset, deploymentNa
 	err = client.AppsV1().Deployments(namespace).Delete(context.TODO(), deploymentName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除Deployment失败, %v", err.Error()))
-		return errors.New("删除Deployment失败," + err.Error())
+		return newAPIError("删除Deployment失败", err)
 	}
 	return nil
 }
 
-// RestartDeployment 重启Deployment
+// RestartDeployment 重启Deployment，修改Pod模板的restartedAt annotation触发滚动更新
 func (d *deployment) RestartDeployment(client *kubernetes.Clientset, deploymentName, namespace string) (err error) {
-	//此功能等同于一下kubectl命令
-	// kubectl patch deployment my-deployment -p \
-	//'{"spec":{"template":{"spec":{"containers":[{"name":"my-container","env":[{"name":"MY_ENV","value":"my-value"}]}]}}}}'
-	// 首先获取该deployment中每个Pod有多少容器，拿到名字
-	deploymentInfo, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
-	if err != nil {
-		zap.L().Error(fmt.Sprintf("获取Deployment失败, %v", err.Error()))
-		return errors.New("获取Deployment失败," + err.Error())
-	}
-	// 遍历deploymentInfo获取所有容器名，放入到containerList
-	var containerListData []map[string]interface{}
-	for _, container := range deploymentInfo.Spec.Template.Spec.Containers {
-		containerData := map[string]interface{}{
-			"name": container.Name,
-			"env": []map[string]interface{}{
-				{
-					"name":  "RESTART_",
-					"value": strconv.FormatInt(time.Now().Unix(), 10),
-				},
-			},
-		}
-		containerListData = append(containerListData, containerData)
-	}
-	// 使用patchData Map组装数据
-	patchData := map[string]interface{}{
-		"spec": map[string]interface{}{
-			"template": map[string]interface{}{
-				"spec": map[string]interface{}{
-					"containers": containerListData,
-				},
-			},
-		},
-	}
-	// 序列化为字节，因为patch方法只接收字节类型参数
-	patchByte, err := json.Marshal(patchData)
-	fmt.Println(string(patchByte))
-	if err != nil {
-		zap.L().Error(fmt.Sprintf("Json序列化失败, %v", err.Error()))
-		return errors.New("Json序列化失败," + err.Error())
-	}
-	// 调用patch方法更新deployment
-	_, err = client.AppsV1().Deployments(namespace).Patch(context.TODO(), deploymentName, types.StrategicMergePatchType, patchByte, metav1.PatchOptions{})
-	if err != nil {
-		zap.L().Error(fmt.Sprintf("重启Deployment失败, %v", err.Error()))
-		return errors.New("重启Deployment失败," + err.Error())
-	}
-	return nil
+	return Workload.Restart(client, KindDeployment, namespace, deploymentName)
 }
 
 // UpdateDeployment 更新Deployment
 func (d *deployment) UpdateDeployment(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var deploy = &appsv1.Deployment{}
-	err = json.Unmarshal([]byte(content), deploy)
+	err = decodeContent(content, deploy)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.AppsV1().Deployments(namespace).Update(context.TODO(), deploy, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新Deployment失败, %v", err.Error()))
-		return errors.New("更新Deployment失败," + err.Error())
+		return newAPIError("更新Deployment失败", err)
 	}
 	return nil
 }
 
 // GetDeployNumPerNp 获取每个Namespace的Deployment的数量
-func (d *deployment) GetDeployNumPerNp(client *kubernetes.Clientset) (deploysNps []*DeploySNp, err error) {
+func (d *deployment) GetDeployNumPerNp(cc *ClusterClient) (deploysNps []*DeploySNp, err error) {
 	// 获取Namespace列表
-	namespaceList, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := cc.Cache.ListNamespaces()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Namespace列表失败, %v", err.Error()))
-		return nil, errors.New("获取Namespace列表失败, " + err.Error())
+		return nil, newAPIError("获取Namespace列表失败", err)
 	}
-	for _, namespace := range namespaceList.Items {
+	for _, namespace := range namespaceList {
 		// 获取Deployment列表
-		deployList, err := client.AppsV1().Deployments(namespace.Name).List(context.TODO(), metav1.ListOptions{})
+		deployList, err := cc.Cache.ListDeployments(namespace.Name)
 		if err != nil {
 			zap.L().Error(fmt.Sprintf("获取Deployment列表失败, %v", err.Error()))
-			return nil, errors.New("获取Deployment列表失败, " + err.Error())
+			return nil, newAPIError("获取Deployment列表失败", err)
 		}
 		// 组装数据
 		deploysNp := &DeploySNp{
 			Namespace:     namespace.Name,
-			DeploymentNum: len(deployList.Items),
+			DeploymentNum: len(deployList),
 		}
 		// 添加数据到podsNps中
 		deploysNps = append(deploysNps, deploysNp)
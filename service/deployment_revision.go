@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Deployment及ReplicaSet上记录版本信息的annotation，与kubectl rollout history一致
+const (
+	RevisionAnnotation    = "deployment.kubernetes.io/revision"
+	ChangeCauseAnnotation = "kubernetes.io/change-cause"
+	// changeCausePath change-cause在json patch中的路径，"/"转义为"~1"
+	changeCausePath = "/metadata/annotations/kubernetes.io~1change-cause"
+)
+
+// DeploymentRevision Deployment的历史版本，每个版本对应一个ReplicaSet
+type DeploymentRevision struct {
+	Revision     int64     `json:"revision"`
+	ReplicaSet   string    `json:"replica_set"`
+	ChangeCause  string    `json:"change_cause"`
+	Images       []string  `json:"images"`
+	Replicas     int32     `json:"replicas"` // 该版本当前的副本数，滚动更新完成后旧版本为0
+	Current      bool      `json:"current"`
+	CreationTime time.Time `json:"creation_time"`
+}
+
+// RevisionDiff 两个版本Pod模板的差异
+type RevisionDiff struct {
+	From    int64          `json:"from"`
+	To      int64          `json:"to"`
+	Changes []*FieldChange `json:"changes"`
+	Diff    string         `json:"diff"` // unified diff
+}
+
+// GetDeploymentRevisions 获取Deployment的历史版本，按版本号倒序
+func (d *deployment) GetDeploymentRevisions(client *kubernetes.Clientset, deploymentName, namespace string) (revisions []*DeploymentRevision, err error) {
+	deploy, replicaSets, err := d.revisionReplicaSets(client, deploymentName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	current := deploy.Annotations[RevisionAnnotation]
+	revisions = make([]*DeploymentRevision, 0, len(replicaSets))
+	for revision, rs := range replicaSets {
+		revisions = append(revisions, &DeploymentRevision{
+			Revision:     revision,
+			ReplicaSet:   rs.Name,
+			ChangeCause:  rs.Annotations[ChangeCauseAnnotation],
+			Images:       podImages(&rs.Spec.Template.Spec),
+			Replicas:     rs.Status.Replicas,
+			Current:      rs.Annotations[RevisionAnnotation] == current,
+			CreationTime: rs.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// DiffDeploymentRevisions 对比两个版本的Pod模板，to为0时与当前版本对比
+func (d *deployment) DiffDeploymentRevisions(client *kubernetes.Clientset, deploymentName, namespace string, from, to int64) (diff *RevisionDiff, err error) {
+	deploy, replicaSets, err := d.revisionReplicaSets(client, deploymentName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		to, _ = strconv.ParseInt(deploy.Annotations[RevisionAnnotation], 10, 64)
+	}
+	fromRS, ok := replicaSets[from]
+	if !ok {
+		return nil, badRequest(fmt.Sprintf("Deployment %s不存在版本%d", deploymentName, from))
+	}
+	toRS, ok := replicaSets[to]
+	if !ok {
+		return nil, badRequest(fmt.Sprintf("Deployment %s不存在版本%d", deploymentName, to))
+	}
+	before, err := templateMap(&fromRS.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	after, err := templateMap(&toRS.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	diff = &RevisionDiff{From: from, To: to, Changes: []*FieldChange{}}
+	diffValues("", before, after, &diff.Changes)
+	if diff.Diff, err = unifiedDiff(before, after); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// RollbackDeployment 回滚到指定版本，revision为0时回滚到上一个版本，效果等同于kubectl rollout undo
+// patch带上读取时的resourceVersion，期间Deployment被修改时apiserver返回冲突，重新读取后重试
+func (d *deployment) RollbackDeployment(client *kubernetes.Clientset, deploymentName, namespace string, revision int64) (err error) {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deploy, replicaSets, err := d.revisionReplicaSets(client, deploymentName, namespace)
+		if err != nil {
+			return err
+		}
+		patchByte, err := d.rollbackPatch(deploy, replicaSets, revision)
+		if err != nil {
+			return err
+		}
+		_, err = client.AppsV1().Deployments(namespace).Patch(context.TODO(), deploymentName, types.JSONPatchType, patchByte, metav1.PatchOptions{})
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("回滚Deployment失败, %v", err.Error()))
+			return newAPIError("回滚Deployment失败", err)
+		}
+		return nil
+	})
+}
+
+// rollbackPatch 生成回滚到目标版本的json patch，只修改Pod模板及change-cause，不覆盖其它annotation
+func (d *deployment) rollbackPatch(deploy *appsv1.Deployment, replicaSets map[int64]*appsv1.ReplicaSet, revision int64) ([]byte, error) {
+	if deploy.Spec.Paused {
+		return nil, badRequest(fmt.Sprintf("Deployment %s已暂停, 恢复后才能回滚", deploy.Name))
+	}
+	current, _ := strconv.ParseInt(deploy.Annotations[RevisionAnnotation], 10, 64)
+	if revision == 0 {
+		// 上一个版本为小于当前版本的最大版本号
+		for r := range replicaSets {
+			if r < current && r > revision {
+				revision = r
+			}
+		}
+		if revision == 0 {
+			return nil, badRequest(fmt.Sprintf("Deployment %s没有可回滚的历史版本", deploy.Name))
+		}
+	}
+	if revision == current {
+		return nil, badRequest(fmt.Sprintf("Deployment %s当前已是版本%d", deploy.Name, revision))
+	}
+	rs, ok := replicaSets[revision]
+	if !ok {
+		return nil, badRequest(fmt.Sprintf("Deployment %s不存在版本%d", deploy.Name, revision))
+	}
+
+	template := rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	// json patch整体替换Pod模板，strategic merge patch会合并容器等列表，无法还原
+	// resourceVersion作为前置条件，读取后Deployment有变化时apiserver返回409
+	patch := []map[string]interface{}{
+		{"op": "replace", "path": "/metadata/resourceVersion", "value": deploy.ResourceVersion},
+		{"op": "replace", "path": "/spec/template", "value": template},
+	}
+	// 回滚后的change-cause使用目标版本的记录，目标版本没有记录时去掉当前的
+	cause, hasCause := rs.Annotations[ChangeCauseAnnotation]
+	_, currentCause := deploy.Annotations[ChangeCauseAnnotation]
+	switch {
+	case hasCause && deploy.Annotations == nil:
+		patch = append(patch, map[string]interface{}{"op": "add", "path": "/metadata/annotations", "value": map[string]string{ChangeCauseAnnotation: cause}})
+	case hasCause:
+		patch = append(patch, map[string]interface{}{"op": "add", "path": changeCausePath, "value": cause})
+	case currentCause:
+		patch = append(patch, map[string]interface{}{"op": "remove", "path": changeCausePath})
+	}
+	patchByte, err := json.Marshal(patch)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("Json序列化失败, %v", err.Error()))
+		return nil, errors.New("Json序列化失败, " + err.Error())
+	}
+	return patchByte, nil
+}
+
+// revisionReplicaSets 获取Deployment及其拥有的ReplicaSet，key为版本号
+func (d *deployment) revisionReplicaSets(client *kubernetes.Clientset, deploymentName, namespace string) (*appsv1.Deployment, map[int64]*appsv1.ReplicaSet, error) {
+	deploy, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取Deployment详情失败, %v", err.Error()))
+		return nil, nil, newAPIError("获取Deployment详情失败", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, nil, errors.New("解析Deployment selector失败, " + err.Error())
+	}
+	list, err := client.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取ReplicaSet列表失败, %v", err.Error()))
+		return nil, nil, newAPIError("获取ReplicaSet列表失败", err)
+	}
+	replicaSets := make(map[int64]*appsv1.ReplicaSet)
+	for i := range list.Items {
+		rs := &list.Items[i]
+		// selector相同的ReplicaSet可能属于其它Deployment，只保留当前Deployment拥有的
+		if !metav1.IsControlledBy(rs, deploy) {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[RevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		replicaSets[revision] = rs
+	}
+	return deploy, replicaSets, nil
+}
+
+// templateMap 转换Pod模板用于对比，去掉每个版本都不同的pod-template-hash
+func templateMap(template *corev1.PodTemplateSpec) (map[string]interface{}, error) {
+	template = template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return nil, errors.New("转换Pod模板失败, " + err.Error())
+	}
+	return data, nil
+}
\ No newline at end of file
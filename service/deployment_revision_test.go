@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func TestRollbackPatch(t *testing.T) {
+	replicaSet := func(revision, cause string) *appsv1.ReplicaSet {
+		annotations := map[string]string{RevisionAnnotation: revision}
+		if cause != "" {
+			annotations[ChangeCauseAnnotation] = cause
+		}
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		revision    int64
+		wantOps     []string
+		wantErr     bool
+	}{
+		{
+			name:        "previous revision with change cause",
+			annotations: map[string]string{RevisionAnnotation: "3", "team": "web"},
+			wantOps:     []string{"replace /metadata/resourceVersion", "replace /spec/template", "add " + changeCausePath},
+		},
+		{
+			name:        "target without change cause removes current",
+			annotations: map[string]string{RevisionAnnotation: "3", ChangeCauseAnnotation: "image v3"},
+			revision:    1,
+			wantOps:     []string{"replace /metadata/resourceVersion", "replace /spec/template", "remove " + changeCausePath},
+		},
+		{
+			name:        "target without change cause keeps annotations",
+			annotations: map[string]string{RevisionAnnotation: "3"},
+			revision:    1,
+			wantOps:     []string{"replace /metadata/resourceVersion", "replace /spec/template"},
+		},
+		{name: "current revision", annotations: map[string]string{RevisionAnnotation: "3"}, revision: 3, wantErr: true},
+		{name: "missing revision", annotations: map[string]string{RevisionAnnotation: "3"}, revision: 7, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", ResourceVersion: "42", Annotations: tt.annotations}}
+			replicaSets := map[int64]*appsv1.ReplicaSet{1: replicaSet("1", ""), 2: replicaSet("2", "image v2"), 3: replicaSet("3", "image v3")}
+			patchByte, err := Deployment.rollbackPatch(deploy, replicaSets, tt.revision)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rollbackPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var patch []map[string]interface{}
+			if err := json.Unmarshal(patchByte, &patch); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			ops := make([]string, 0, len(patch))
+			for _, op := range patch {
+				ops = append(ops, op["op"].(string)+" "+op["path"].(string))
+			}
+			if !reflect.DeepEqual(ops, tt.wantOps) {
+				t.Errorf("ops = %v, want %v", ops, tt.wantOps)
+			}
+			if patch[0]["value"] != "42" {
+				t.Errorf("resourceVersion = %v, want 42", patch[0]["value"])
+			}
+		})
+	}
+}
\ No newline at end of file
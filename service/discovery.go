@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"sort"
+)
+
+var Capability capability
+
+type capability struct{}
+
+// 各资源使用的API版本，低版本集群可能没有，处理请求前先确认集群是否支持
+var (
+	IngressGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	JobGVR     = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	CronJobGVR = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+	CRDGVR     = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// ErrNotSupported 集群不支持请求的资源，调用方可以用errors.Is判断
+var ErrNotSupported = errors.New("当前集群不支持该资源")
+
+// Capabilities 集群的版本、API组及CRD信息
+type Capabilities struct {
+	Cluster    string      `json:"cluster"`
+	Version    string      `json:"version"`  // v1.24.2
+	Platform   string      `json:"platform"` // linux/amd64
+	Groups     []*APIGroup `json:"groups"`
+	CRDs       []*CRDInfo  `json:"crds"`
+	CRDMessage string      `json:"crd_message"` // CRD获取失败时的原因，不影响其它信息的返回
+}
+
+// APIGroup 集群提供的API组及版本
+type APIGroup struct {
+	Name             string   `json:"name"` // core组的名称为空
+	PreferredVersion string   `json:"preferred_version"`
+	Versions         []string `json:"versions"`
+}
+
+// CRDInfo 集群中安装的CRD
+type CRDInfo struct {
+	Name     string   `json:"name"`
+	Group    string   `json:"group"`
+	Kind     string   `json:"kind"`
+	Plural   string   `json:"plural"`
+	Scope    string   `json:"scope"`    // Namespaced、Cluster
+	Versions []string `json:"versions"` // 提供服务的版本
+}
+
+// GetCapabilities 通过discovery获取集群的版本、API组及版本，以及已安装的CRD
+func (c *capability) GetCapabilities(cc *ClusterClient) (capabilities *Capabilities, err error) {
+	info, err := cc.Discovery.ServerVersion()
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取集群版本失败, %v", err.Error()))
+		return nil, errors.New("获取集群版本失败, " + err.Error())
+	}
+	groupList, err := cc.Discovery.ServerGroups()
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取集群API组失败, %v", err.Error()))
+		return nil, errors.New("获取集群API组失败, " + err.Error())
+	}
+	capabilities = &Capabilities{
+		Cluster:  cc.Name,
+		Version:  info.GitVersion,
+		Platform: info.Platform,
+		Groups:   make([]*APIGroup, 0, len(groupList.Groups)),
+		CRDs:     make([]*CRDInfo, 0),
+	}
+	for _, group := range groupList.Groups {
+		apiGroup := &APIGroup{
+			Name:             group.Name,
+			PreferredVersion: group.PreferredVersion.Version,
+		}
+		for _, version := range group.Versions {
+			apiGroup.Versions = append(apiGroup.Versions, version.Version)
+		}
+		capabilities.Groups = append(capabilities.Groups, apiGroup)
+	}
+
+	crds, err := c.listCRDs(cc)
+	if err != nil {
+		capabilities.CRDMessage = err.Error()
+		return capabilities, nil
+	}
+	capabilities.CRDs = crds
+	return capabilities, nil
+}
+
+// CheckResource 确认集群是否提供指定的资源，不支持时返回ErrNotSupported，用于替代client-go返回的404
+func (c *capability) CheckResource(cc *ClusterClient, gvr schema.GroupVersionResource) error {
+	supported, err := c.IsSupported(cc, gvr)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		zap.L().Warn("resource not supported", zap.String("cluster", cc.Name), zap.String("resource", gvr.String()))
+		return fmt.Errorf("%w, 集群: %s, 资源: %s/%s", ErrNotSupported, cc.Name, gvr.GroupVersion().String(), gvr.Resource)
+	}
+	return nil
+}
+
+// IsSupported 判断集群是否提供指定的资源，结果来自discovery缓存
+// 缓存中没有时刷新一次缓存后重新查询，避免启动后新增的API组或CRD一直被判断为不支持
+func (c *capability) IsSupported(cc *ClusterClient, gvr schema.GroupVersionResource) (bool, error) {
+	supported, err := c.lookupResource(cc, gvr)
+	if err != nil || supported {
+		return supported, err
+	}
+	if !cc.RefreshDiscovery() {
+		return false, nil
+	}
+	return c.lookupResource(cc, gvr)
+}
+
+// lookupResource 在discovery缓存中查找资源，分组或版本不存在时返回false
+func (c *capability) lookupResource(cc *ClusterClient, gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := cc.Discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if apierrors.IsNotFound(err) || errors.Is(err, memory.ErrCacheNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取集群API资源失败, %v", err.Error()))
+		return false, errors.New("获取集群API资源失败, " + err.Error())
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listCRDs 获取集群中安装的CRD，按名称排序
+func (c *capability) listCRDs(cc *ClusterClient) ([]*CRDInfo, error) {
+	if err := c.CheckResource(cc, CRDGVR); err != nil {
+		return nil, err
+	}
+	list, err := cc.Dynamic.Resource(CRDGVR).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取CRD列表失败, %v", err.Error()))
+		return nil, errors.New("获取CRD列表失败, " + err.Error())
+	}
+	crds := make([]*CRDInfo, 0, len(list.Items))
+	for i := range list.Items {
+		crds = append(crds, toCRDInfo(&list.Items[i]))
+	}
+	sort.Slice(crds, func(i, j int) bool {
+		return crds[i].Name < crds[j].Name
+	})
+	return crds, nil
+}
\ No newline at end of file
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+)
+
+// APIError apiserver返回的错误，Code为对应的HTTP状态码，前端可以按Reason区分冲突、不存在、无权限及校验失败
+type APIError struct {
+	Code    int                 `json:"code"`
+	Reason  metav1.StatusReason `json:"reason"` // Conflict、NotFound、Forbidden、Invalid等
+	Message string              `json:"message"`
+	Causes  []*FieldCause       `json:"causes,omitempty"` // Invalid时校验失败的字段
+	err     error
+}
+
+// FieldCause 校验失败的字段及原因
+type FieldCause struct {
+	Field   string `json:"field"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// statusCodes apiserver错误原因对应的HTTP状态码，未列出的使用apiserver返回的状态码
+var statusCodes = map[metav1.StatusReason]int{
+	metav1.StatusReasonConflict:        http.StatusConflict,
+	metav1.StatusReasonAlreadyExists:   http.StatusConflict,
+	metav1.StatusReasonNotFound:        http.StatusNotFound,
+	metav1.StatusReasonForbidden:       http.StatusForbidden,
+	metav1.StatusReasonUnauthorized:    http.StatusUnauthorized,
+	metav1.StatusReasonInvalid:         http.StatusUnprocessableEntity,
+	metav1.StatusReasonBadRequest:      http.StatusBadRequest,
+	metav1.StatusReasonGone:            http.StatusGone,
+	metav1.StatusReasonTooManyRequests: http.StatusTooManyRequests,
+	metav1.StatusReasonTimeout:         http.StatusGatewayTimeout,
+	metav1.StatusReasonServerTimeout:   http.StatusGatewayTimeout,
+}
+
+// newAPIError 在err前加上操作描述，如"更新Deployment失败"，err为apiserver的错误时转换成APIError，否则返回普通错误
+// err已经是APIError时保留其状态码及原因，只更新描述
+func newAPIError(msg string, err error) error {
+	message := fmt.Sprintf("%s, %v", msg, err)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return &APIError{Code: apiErr.Code, Reason: apiErr.Reason, Message: message, Causes: apiErr.Causes, err: err}
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return errors.New(message)
+	}
+	apiErr = &APIError{
+		Code:    http.StatusInternalServerError,
+		Reason:  apierrors.ReasonForError(err),
+		Message: message,
+		err:     err,
+	}
+	if code, ok := statusCodes[apiErr.Reason]; ok {
+		apiErr.Code = code
+	} else if code := int(status.Status().Code); code >= http.StatusBadRequest {
+		apiErr.Code = code
+	}
+	if details := status.Status().Details; details != nil {
+		for _, cause := range details.Causes {
+			apiErr.Causes = append(apiErr.Causes, &FieldCause{Field: cause.Field, Type: string(cause.Type), Message: cause.Message})
+		}
+	}
+	return apiErr
+}
+
+// badRequest 请求参数与资源当前状态不符时的错误，如回滚到不存在的版本，返回400
+func badRequest(msg string) error {
+	return &APIError{Code: http.StatusBadRequest, Reason: metav1.StatusReasonBadRequest, Message: msg}
+}
+
+// conflict 资源已存在时的错误，如注册同名集群，返回409
+func conflict(msg string) error {
+	return &APIError{Code: http.StatusConflict, Reason: metav1.StatusReasonAlreadyExists, Message: msg}
+}
+
+// notFound 请求的资源不存在时的错误，如更新未注册的集群，返回404
+func notFound(msg string) error {
+	return &APIError{Code: http.StatusNotFound, Reason: metav1.StatusReasonNotFound, Message: msg}
+}
\ No newline at end of file
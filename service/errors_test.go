@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	invalid := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+	tests := []struct {
+		name       string
+		err        error
+		wantAPI    bool
+		wantCode   int
+		wantReason metav1.StatusReason
+		wantCauses int
+	}{
+		{name: "conflict", err: apierrors.NewConflict(deployments, "web", errors.New("modified")), wantAPI: true, wantCode: http.StatusConflict, wantReason: metav1.StatusReasonConflict},
+		{name: "already exists", err: apierrors.NewAlreadyExists(deployments, "web"), wantAPI: true, wantCode: http.StatusConflict, wantReason: metav1.StatusReasonAlreadyExists},
+		{name: "not found", err: apierrors.NewNotFound(deployments, "web"), wantAPI: true, wantCode: http.StatusNotFound, wantReason: metav1.StatusReasonNotFound},
+		{name: "forbidden", err: apierrors.NewForbidden(deployments, "web", errors.New("rbac")), wantAPI: true, wantCode: http.StatusForbidden, wantReason: metav1.StatusReasonForbidden},
+		{name: "invalid with causes", err: invalid, wantAPI: true, wantCode: http.StatusUnprocessableEntity, wantReason: metav1.StatusReasonInvalid, wantCauses: 1},
+		{name: "unlisted reason uses status code", err: apierrors.NewServiceUnavailable("etcd down"), wantAPI: true, wantCode: http.StatusServiceUnavailable, wantReason: metav1.StatusReasonServiceUnavailable},
+		{name: "internal error", err: apierrors.NewInternalError(errors.New("boom")), wantAPI: true, wantCode: http.StatusInternalServerError, wantReason: metav1.StatusReasonInternalError, wantCauses: 1},
+		{name: "wrapped apiserver error", err: fmt.Errorf("retry: %w", apierrors.NewNotFound(deployments, "web")), wantAPI: true, wantCode: http.StatusNotFound, wantReason: metav1.StatusReasonNotFound},
+		{name: "bad request is kept", err: badRequest("content为空"), wantAPI: true, wantCode: http.StatusBadRequest, wantReason: metav1.StatusReasonBadRequest},
+		{name: "plain error", err: errors.New("dial tcp: i/o timeout")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError("更新Deployment失败", tt.err)
+			if want := "更新Deployment失败, " + tt.err.Error(); err.Error() != want {
+				t.Errorf("message = %q, want %q", err.Error(), want)
+			}
+			var apiErr *APIError
+			if got := errors.As(err, &apiErr); got != tt.wantAPI {
+				t.Fatalf("errors.As(*APIError) = %v, want %v", got, tt.wantAPI)
+			}
+			if !tt.wantAPI {
+				return
+			}
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("code = %v, want %v", apiErr.Code, tt.wantCode)
+			}
+			if apiErr.Reason != tt.wantReason {
+				t.Errorf("reason = %v, want %v", apiErr.Reason, tt.wantReason)
+			}
+			if len(apiErr.Causes) != tt.wantCauses {
+				t.Errorf("causes = %v, want %v", len(apiErr.Causes), tt.wantCauses)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -2,12 +2,12 @@ package service
 
 import (
 	"fmt"
+	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 	"k8sManagerApi/dao"
 	"k8sManagerApi/model"
-	"time"
 )
 
 var Event event
@@ -23,10 +23,8 @@ func (e *event) GetEvents(name, cluster string, page, limit int) (events *dao.Ev
 	return data, nil
 }
 
-// WatchEventTask informer监听event
-func (e *event) WatchEventTask(cluster string) {
-	// 实例化 informerFactory
-	informerFactory := informers.NewSharedInformerFactory(K8s.ClientMap[cluster], time.Minute)
+// WatchEventTask informer监听event，与列表缓存共用集群的informerFactory，stopCh关闭时退出，用于集群移除时停止监听
+func (e *event) WatchEventTask(cluster string, informerFactory informers.SharedInformerFactory, stopCh chan struct{}) {
 	// 监听资源
 	informer := informerFactory.Core().V1().Events()
 	// 添加事件handler
@@ -40,15 +38,14 @@ func (e *event) WatchEventTask(cluster string) {
 	if err != nil {
 		return
 	}
-	// 处理启动和优雅关闭
-	stopCh := make(chan struct{})
-	defer close(stopCh)
+	// 处理启动和优雅关闭，factory已经启动时，Start只会启动新注册的event informer
 	informerFactory.Start(stopCh)
 	if !cache.WaitForCacheSync(stopCh, informer.Informer().HasSynced) {
 		fmt.Println("同步cache超时")
 		return
 	}
 	<-stopCh
+	zap.L().Info("stop watch event task", zap.String("cluster", cluster))
 	return
 }
 
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	"strings"
+)
+
+var Generic generic
+
+type generic struct{}
+
+// ResourceResp 通用资源列表的返回内容
+type ResourceResp struct {
+	Total int                         `json:"total"`
+	Items []unstructured.Unstructured `json:"items"`
+	PageInfo
+}
+
+// ResourceMapping gvr解析后的资源信息，Namespaced为false时是集群级别的资源，namespace参数被忽略
+type ResourceMapping struct {
+	GVR        schema.GroupVersionResource
+	Kind       string
+	Namespaced bool
+}
+
+// Resolve 通过discovery解析资源，res为资源的复数或单数名称，如deployments，version为空时使用集群的首选版本
+// 缓存中没有时刷新一次discovery缓存后重试，启动后安装的CRD也可以识别
+func (g *generic) Resolve(cc *ClusterClient, group, version, res string) (*ResourceMapping, error) {
+	if res == "" {
+		return nil, badRequest("资源名称不能为空")
+	}
+	if err := validateResource(group, version, res); err != nil {
+		return nil, err
+	}
+	target := schema.GroupVersionResource{Group: group, Version: version, Resource: res}
+	gvk, err := g.kindFor(cc, target)
+	if meta.IsNoMatchError(err) && cc.RefreshDiscovery() {
+		gvk, err = g.kindFor(cc, target)
+	}
+	if err != nil {
+		return nil, g.mappingError(cc, target.String(), err)
+	}
+	return g.ResolveKind(cc, gvk)
+}
+
+// ResolveKind 通过discovery解析gvk对应的资源，用于按内容中的apiVersion、kind处理资源，未命中时同样刷新一次缓存后重试
+func (g *generic) ResolveKind(cc *ClusterClient, gvk schema.GroupVersionKind) (*ResourceMapping, error) {
+	mapping, err := cc.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) && cc.RefreshDiscovery() {
+		mapping, err = cc.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, g.mappingError(cc, gvk.String(), err)
+	}
+	return &ResourceMapping{
+		GVR:        mapping.Resource,
+		Kind:       gvk.Kind,
+		Namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+	}, nil
+}
+
+// validateResource 校验group、version及资源名称的格式，如资源名称中带有"/"的子资源，格式错误时返回400
+// RESTMapper匹配时不区分资源名称的大小写，校验时同样忽略
+func validateResource(group, version, res string) error {
+	if errs := validation.IsDNS1123Subdomain(strings.ToLower(res)); len(errs) > 0 {
+		return badRequest(fmt.Sprintf("资源名称%s格式错误, %s", res, strings.Join(errs, "; ")))
+	}
+	if errs := validation.IsDNS1123Subdomain(group); group != "" && len(errs) > 0 {
+		return badRequest(fmt.Sprintf("group %s格式错误, %s", group, strings.Join(errs, "; ")))
+	}
+	if errs := validation.IsDNS1123Label(version); version != "" && len(errs) > 0 {
+		return badRequest(fmt.Sprintf("version %s格式错误, %s", version, strings.Join(errs, "; ")))
+	}
+	return nil
+}
+
+// kindFor 将资源名称解析成gvk
+func (g *generic) kindFor(cc *ClusterClient, target schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvr, err := cc.Mapper.ResourceFor(target)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return cc.Mapper.KindFor(gvr)
+}
+
+// GetResources 获取资源列表，支持过滤、排序、分页，page模式下直接请求apiserver获取全量数据
+func (g *generic) GetResources(cc *ClusterClient, mapping *ResourceMapping, namespace string, query *DataSelectQuery) (resourceResp *ResourceResp, err error) {
+	client := g.client(cc, mapping, namespace)
+	if query.ServerSide() {
+		list, err := client.List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取%s列表失败, %v", mapping.Kind, err.Error()))
+			return nil, newAPIError(fmt.Sprintf("获取%s列表失败", mapping.Kind), err)
+		}
+		cells, total, pageInfo := query.selectPage(g.toCells(list.Items), metav1.ListMeta{
+			Continue:           list.GetContinue(),
+			RemainingItemCount: list.GetRemainingItemCount(),
+		})
+		return &ResourceResp{Total: total, Items: g.fromCells(cells), PageInfo: pageInfo}, nil
+	}
+	list, err := client.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取%s列表失败, %v", mapping.Kind, err.Error()))
+		return nil, newAPIError(fmt.Sprintf("获取%s列表失败", mapping.Kind), err)
+	}
+	// 实例化dataSelector结构体，组装数据
+	selectableData := &dataSelector{
+		GenericDataList: g.toCells(list.Items),
+		DataSelect:      query,
+	}
+	filtered := selectableData.Filter()
+	total := len(filtered.GenericDataList)
+	data := filtered.Sort().Paginate()
+	resourceResp = &ResourceResp{
+		Total:    total,
+		Items:    g.fromCells(data.GenericDataList),
+		PageInfo: PageInfo{Mode: PaginateModePage},
+	}
+	return resourceResp, nil
+}
+
+// GetResourceDetail 获取资源详情
+func (g *generic) GetResourceDetail(cc *ClusterClient, mapping *ResourceMapping, namespace, name string) (obj *unstructured.Unstructured, err error) {
+	obj, err = g.client(cc, mapping, namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取%s详情失败, %v", mapping.Kind, err.Error()))
+		return nil, newAPIError(fmt.Sprintf("获取%s详情失败", mapping.Kind), err)
+	}
+	return obj, nil
+}
+
+// CreateResource 创建资源，content为json或yaml，只能包含一个资源，未指定namespace时使用参数中的namespace
+func (g *generic) CreateResource(cc *ClusterClient, mapping *ResourceMapping, namespace, content string) (err error) {
+	obj, err := g.decode(mapping, namespace, content)
+	if err != nil {
+		return err
+	}
+	_, err = g.client(cc, mapping, obj.GetNamespace()).Create(context.TODO(), obj, metav1.CreateOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("创建%s %s失败, %v", mapping.Kind, obj.GetName(), err.Error()))
+		return newAPIError(fmt.Sprintf("创建%s %s失败", mapping.Kind, obj.GetName()), err)
+	}
+	return nil
+}
+
+// UpdateResource 更新资源，content为json或yaml，只能包含一个资源，需要包含resourceVersion
+func (g *generic) UpdateResource(cc *ClusterClient, mapping *ResourceMapping, namespace, content string) (err error) {
+	obj, err := g.decode(mapping, namespace, content)
+	if err != nil {
+		return err
+	}
+	_, err = g.client(cc, mapping, obj.GetNamespace()).Update(context.TODO(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("更新%s %s失败, %v", mapping.Kind, obj.GetName(), err.Error()))
+		return newAPIError(fmt.Sprintf("更新%s %s失败", mapping.Kind, obj.GetName()), err)
+	}
+	return nil
+}
+
+// DeleteResource 删除资源
+func (g *generic) DeleteResource(cc *ClusterClient, mapping *ResourceMapping, namespace, name string) (err error) {
+	err = g.client(cc, mapping, namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("删除%s失败, %v", mapping.Kind, err.Error()))
+		return newAPIError(fmt.Sprintf("删除%s失败", mapping.Kind), err)
+	}
+	return nil
+}
+
+// client 获取资源的dynamic client，集群级别的资源不区分namespace
+func (g *generic) client(cc *ClusterClient, mapping *ResourceMapping, namespace string) dynamic.ResourceInterface {
+	if !mapping.Namespaced {
+		return cc.Dynamic.Resource(mapping.GVR)
+	}
+	return cc.Dynamic.Resource(mapping.GVR).Namespace(namespace)
+}
+
+// decode 反序列化资源，并校验kind与请求的资源一致，避免用其它资源的内容误更新
+func (g *generic) decode(mapping *ResourceMapping, namespace, content string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := decodeContent(content, &obj.Object); err != nil {
+		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
+		return nil, badRequest("反序列化失败, " + err.Error())
+	}
+	if obj.GetKind() == "" {
+		obj.SetAPIVersion(mapping.GVR.GroupVersion().String())
+		obj.SetKind(mapping.Kind)
+	}
+	if obj.GetKind() != mapping.Kind {
+		return nil, badRequest(fmt.Sprintf("资源类型不匹配, 请求的是%s, 内容中是%s", mapping.Kind, obj.GetKind()))
+	}
+	if mapping.Namespaced && obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	return obj, nil
+}
+
+// mappingError 资源在集群中不存在时返回ErrNotSupported，资源名称对应多个资源时返回400并列出候选资源
+func (g *generic) mappingError(cc *ClusterClient, target string, err error) error {
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("%w, 集群: %s, 资源: %s", ErrNotSupported, cc.Name, target)
+	}
+	var ambiguous *meta.AmbiguousResourceError
+	if errors.As(err, &ambiguous) {
+		candidates := make([]string, 0, len(ambiguous.MatchingResources)+len(ambiguous.MatchingKinds))
+		for _, gvr := range ambiguous.MatchingResources {
+			candidates = append(candidates, gvr.GroupVersion().String()+"/"+gvr.Resource)
+		}
+		for _, gvk := range ambiguous.MatchingKinds {
+			candidates = append(candidates, gvk.GroupVersion().String()+"/"+gvk.Kind)
+		}
+		return badRequest(fmt.Sprintf("资源%s对应多个资源, 请指定group及version: %s", target, strings.Join(candidates, ", ")))
+	}
+	zap.L().Error(fmt.Sprintf("解析资源失败, %v", err.Error()))
+	return newAPIError("解析资源失败", err)
+}
+
+// toCells unstructured.Unstructured -> DataCell
+func (g *generic) toCells(objs []unstructured.Unstructured) []DataCell {
+	cells := make([]DataCell, len(objs))
+	for i := range objs {
+		cells[i] = unstructuredCell(objs[i])
+	}
+	return cells
+}
+
+// fromCells DataCell -> unstructured.Unstructured
+func (g *generic) fromCells(cells []DataCell) []unstructured.Unstructured {
+	objs := make([]unstructured.Unstructured, len(cells))
+	for i := range cells {
+		objs[i] = unstructured.Unstructured(cells[i].(unstructuredCell))
+	}
+	return objs
+}
\ No newline at end of file
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"testing"
+)
+
+func TestMappingError(t *testing.T) {
+	cc := &ClusterClient{Name: "dev"}
+	tests := []struct {
+		name         string
+		err          error
+		wantNotFound bool
+		wantCode     int
+	}{
+		{
+			name:         "no match",
+			err:          &meta.NoResourceMatchError{PartialResource: schema.GroupVersionResource{Resource: "widgets"}},
+			wantNotFound: true,
+		},
+		{
+			name: "ambiguous resource",
+			err: &meta.AmbiguousResourceError{
+				PartialResource: schema.GroupVersionResource{Resource: "events"},
+				MatchingResources: []schema.GroupVersionResource{
+					{Version: "v1", Resource: "events"},
+					{Group: "events.k8s.io", Version: "v1", Resource: "events"},
+				},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{name: "other error", err: errors.New("discovery failed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Generic.mappingError(cc, "events", tt.err)
+			if got := errors.Is(err, ErrNotSupported); got != tt.wantNotFound {
+				t.Errorf("errors.Is(ErrNotSupported) = %v, want %v", got, tt.wantNotFound)
+			}
+			var apiErr *APIError
+			code := 0
+			if errors.As(err, &apiErr) {
+				code = apiErr.Code
+			}
+			if code != tt.wantCode {
+				t.Errorf("code = %v, want %v, error: %v", code, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestValidateResource(t *testing.T) {
+	tests := []struct {
+		group, version, res string
+		wantErr             bool
+	}{
+		{res: "deployments", group: "apps", version: "v1"},
+		{res: "Deployments"},
+		{res: "deployments.apps"},
+		{res: "pods/log", wantErr: true},
+		{res: "pods", group: "Apps_1", wantErr: true},
+		{res: "pods", version: "v1.2", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateResource(tt.group, tt.version, tt.res); (err != nil) != tt.wantErr {
+			t.Errorf("validateResource(%q, %q, %q) error = %v, wantErr %v", tt.group, tt.version, tt.res, err, tt.wantErr)
+		}
+	}
+}
\ No newline at end of file
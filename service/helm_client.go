@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"go.uber.org/zap"
 	"helm.sh/helm/v3/pkg/action"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"log"
 	"os"
 )
@@ -19,22 +23,50 @@ type helmConfig struct {
 
 // GetAc 获取Helm action 配置
 func (c *helmConfig) GetAc(cluster, namespace string) (*action.Configuration, error) {
-	kubeconfig := K8s.GetClusterConf(cluster)
-	fmt.Println("kube配置文件：", kubeconfig)
-	if kubeconfig == "" {
+	cc, err := K8s.GetClusterClient(cluster)
+	if err != nil {
 		zap.L().Warn(fmt.Sprintf("集群不存在: %s, 无法获取client", cluster))
-		return nil, errors.New(fmt.Sprintf("集群不存在: %s, 无法获取client", cluster))
+		return nil, err
 	}
 
-	// new一个actionConfig对象
+	// new一个actionConfig对象，RESTClientGetter使用集群注册表中缓存的配置及discovery
 	actionConfig := new(action.Configuration)
-	cf := &genericclioptions.ConfigFlags{
-		KubeConfig: &kubeconfig,
-		Namespace:  &namespace,
-	}
-	if err := actionConfig.Init(cf, namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(cc.helmGetter.withNamespace(namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		zap.L().Error(fmt.Sprintf("actionConfig初始化失败, %v", err.Error()))
 		return nil, errors.New("actionConfig初始化失败, " + err.Error())
 	}
 	return actionConfig, nil
+}
+
+// restClientGetter 实现genericclioptions.RESTClientGetter接口，供helm使用
+// 同一集群共用rest.Config、discovery缓存及RESTMapper，只有namespace随请求变化
+type restClientGetter struct {
+	client    *ClusterClient
+	namespace string
+}
+
+// withNamespace 返回指定namespace的RESTClientGetter
+func (g *restClientGetter) withNamespace(namespace string) *restClientGetter {
+	return &restClientGetter{client: g.client, namespace: namespace}
+}
+
+// ToRESTConfig 返回rest.Config的副本，helm内部会修改该配置
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.client.RestConfig), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	return g.client.Discovery, nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	return g.client.Mapper, nil
+}
+
+// ToRawKubeConfigLoader helm只通过它获取默认namespace
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	conf := clientcmdapi.NewConfig()
+	conf.Contexts["default"] = &clientcmdapi.Context{Namespace: g.namespace}
+	conf.CurrentContext = "default"
+	return clientcmd.NewDefaultClientConfig(*conf, &clientcmd.ConfigOverrides{})
 }
\ No newline at end of file
@@ -91,10 +91,10 @@ func (h *helmStore) InstallRelease(actionConfig *action.Configuration, cluster,
 	client.Namespace = namespace
 	splitChart := strings.Split(chart, ".")
 	if splitChart[len(splitChart)-1] == "tgz" && strings.Contains(chart, ":") {
-		chart = config.Conf.UploadPath + chart
+		chart = config.Get().UploadPath + chart
 	}
 	// 加载chart文件，并基于文件内容生成k8的资源
-	chart = filepath.Join(config.Conf.UploadPath, chart)
+	chart = filepath.Join(config.Get().UploadPath, chart)
 	chartRequested, err := loader.Load(chart)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("加载Chart文件, %v", err.Error()))
@@ -129,7 +129,7 @@ func (h *helmStore) UploadChartFile(file multipart.File, header *multipart.FileH
 		return errors.New(fmt.Sprintf("Chart文件必须以.tgz结尾"))
 	}
 
-	filePath := config.Conf.UploadPath + filename
+	filePath := config.Get().UploadPath + filename
 	_, err := os.Stat(filePath)
 	if os.IsExist(err) {
 		zap.L().Warn("Chart文件已存在")
@@ -152,7 +152,7 @@ func (h *helmStore) UploadChartFile(file multipart.File, header *multipart.FileH
 // DeleteChartFile Chart文件删除
 func (h *helmStore) DeleteChartFile(chart string) error {
 	//filePath := config.UploadPath + "/" + chart
-	filePath := config.Conf.UploadPath + chart
+	filePath := config.Get().UploadPath + chart
 	_, err := os.Stat(filePath)
 	if err != nil || os.IsNotExist(err) {
 		zap.L().Warn("Chart文件不存在")
@@ -186,7 +186,7 @@ func (h *helmStore) scpChartToServer(cluster, chart string) (err error) {
 			zap.L().Error("连接集群的node节点失败")
 		}
 		// 文件拷贝
-		srcChartPath := filepath.Join(config.Conf.UploadPath, chart)
+		srcChartPath := filepath.Join(config.Get().UploadPath, chart)
 		destChartPath := filepath.Join("/tmp", chart)
 		err = sftp.ScpToServer(srcChartPath, destChartPath)
 		if err != nil {
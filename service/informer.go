@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	nwv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sort"
+	"time"
+)
+
+// informer全量resync的间隔，lister的数据由watch实时更新，这里只做兜底
+const informerResyncPeriod = 10 * time.Minute
+
+// 缓存的资源类型，同时作为同步状态中的key
+const (
+	cachePods         = "pods"
+	cacheDeployments  = "deployments"
+	cacheDaemonSets   = "daemonsets"
+	cacheStatefulSets = "statefulsets"
+	cacheServices     = "services"
+	cacheIngresses    = "ingresses"
+	cacheConfigMaps   = "configmaps"
+	cacheSecrets      = "secrets"
+	cachePvcs         = "persistentvolumeclaims"
+	cachePvs          = "persistentvolumes"
+	cacheNodes        = "nodes"
+	cacheNamespaces   = "namespaces"
+)
+
+// clusterCache 单个集群的informer缓存，列表接口优先从lister读取，未同步完成前回退到直接请求apiserver
+type clusterCache struct {
+	cluster string
+	client  *kubernetes.Clientset
+	factory informers.SharedInformerFactory
+	// synced 各资源informer的HasSynced方法
+	synced map[string]cache.InformerSynced
+}
+
+// CacheStatus 集群informer缓存的同步状态
+type CacheStatus struct {
+	Cluster   string          `json:"cluster"`
+	Synced    bool            `json:"synced"`    // 所有资源均已同步
+	Resources map[string]bool `json:"resources"` // 各资源的同步状态
+}
+
+// newClusterCache 创建informer缓存，informer需要在Start之前注册，这里一次性注册所有资源
+// withIngress为false时不注册networking/v1的Ingress，集群没有该API时informer永远无法同步，列表接口直接请求apiserver
+func newClusterCache(cluster string, client *kubernetes.Clientset, withIngress bool) *clusterCache {
+	factory := informers.NewSharedInformerFactory(client, informerResyncPeriod)
+	c := &clusterCache{
+		cluster: cluster,
+		client:  client,
+		factory: factory,
+		synced: map[string]cache.InformerSynced{
+			cachePods:         factory.Core().V1().Pods().Informer().HasSynced,
+			cacheDeployments:  factory.Apps().V1().Deployments().Informer().HasSynced,
+			cacheDaemonSets:   factory.Apps().V1().DaemonSets().Informer().HasSynced,
+			cacheStatefulSets: factory.Apps().V1().StatefulSets().Informer().HasSynced,
+			cacheServices:     factory.Core().V1().Services().Informer().HasSynced,
+			cacheConfigMaps:   factory.Core().V1().ConfigMaps().Informer().HasSynced,
+			cacheSecrets:      factory.Core().V1().Secrets().Informer().HasSynced,
+			cachePvcs:         factory.Core().V1().PersistentVolumeClaims().Informer().HasSynced,
+			cachePvs:          factory.Core().V1().PersistentVolumes().Informer().HasSynced,
+			cacheNodes:        factory.Core().V1().Nodes().Informer().HasSynced,
+			cacheNamespaces:   factory.Core().V1().Namespaces().Informer().HasSynced,
+		},
+	}
+	if withIngress {
+		c.synced[cacheIngresses] = factory.Networking().V1().Ingresses().Informer().HasSynced
+	}
+	return c
+}
+
+// start 启动所有informer，stopCh关闭时退出，同步完成后记录日志
+func (c *clusterCache) start(stopCh chan struct{}) {
+	c.factory.Start(stopCh)
+	go func() {
+		synced := make([]cache.InformerSynced, 0, len(c.synced))
+		for _, fn := range c.synced {
+			synced = append(synced, fn)
+		}
+		if !cache.WaitForCacheSync(stopCh, synced...) {
+			zap.L().Warn("informer cache stopped before synced", zap.String("cluster", c.cluster))
+			return
+		}
+		zap.L().Info("informer cache synced", zap.String("cluster", c.cluster))
+	}()
+}
+
+// hasSynced 判断资源的informer是否已完成首次同步
+func (c *clusterCache) hasSynced(resource string) bool {
+	fn, ok := c.synced[resource]
+	return ok && fn()
+}
+
+// status 获取缓存的同步状态
+func (c *clusterCache) status() *CacheStatus {
+	status := &CacheStatus{Cluster: c.cluster, Synced: true, Resources: map[string]bool{}}
+	for resource, fn := range c.synced {
+		status.Resources[resource] = fn()
+		if !status.Resources[resource] {
+			status.Synced = false
+		}
+	}
+	return status
+}
+
+// GetCacheStatusList 获取所有集群informer缓存的同步状态，按集群名排序
+func (k *k8s) GetCacheStatusList() []*CacheStatus {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	list := make([]*CacheStatus, 0, len(k.clusters))
+	for _, cc := range k.clusters {
+		list = append(list, cc.Cache.status())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Cluster < list[j].Cluster
+	})
+	return list
+}
+
+// 以下为各资源的列表方法，namespace为空时获取所有namespace的数据
+// lister返回的是缓存中对象的指针，这里浅拷贝成值返回，调用方只读，不能修改其中的map、slice等字段
+
+// ListPods 获取Pod列表
+func (c *clusterCache) ListPods(namespace string) ([]corev1.Pod, error) {
+	if c.hasSynced(cachePods) {
+		objs, err := c.factory.Core().V1().Pods().Lister().Pods(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cachePods, err)
+	}
+	list, err := c.client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListDeployments 获取Deployment列表
+func (c *clusterCache) ListDeployments(namespace string) ([]appsv1.Deployment, error) {
+	if c.hasSynced(cacheDeployments) {
+		objs, err := c.factory.Apps().V1().Deployments().Lister().Deployments(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheDeployments, err)
+	}
+	list, err := c.client.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListDaemonSets 获取DaemonSet列表
+func (c *clusterCache) ListDaemonSets(namespace string) ([]appsv1.DaemonSet, error) {
+	if c.hasSynced(cacheDaemonSets) {
+		objs, err := c.factory.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheDaemonSets, err)
+	}
+	list, err := c.client.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListStatefulSets 获取StatefulSet列表
+func (c *clusterCache) ListStatefulSets(namespace string) ([]appsv1.StatefulSet, error) {
+	if c.hasSynced(cacheStatefulSets) {
+		objs, err := c.factory.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheStatefulSets, err)
+	}
+	list, err := c.client.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListServices 获取Service列表
+func (c *clusterCache) ListServices(namespace string) ([]corev1.Service, error) {
+	if c.hasSynced(cacheServices) {
+		objs, err := c.factory.Core().V1().Services().Lister().Services(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheServices, err)
+	}
+	list, err := c.client.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListIngresses 获取Ingress列表
+func (c *clusterCache) ListIngresses(namespace string) ([]nwv1.Ingress, error) {
+	if c.hasSynced(cacheIngresses) {
+		objs, err := c.factory.Networking().V1().Ingresses().Lister().Ingresses(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheIngresses, err)
+	}
+	list, err := c.client.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListConfigMaps 获取ConfigMap列表
+func (c *clusterCache) ListConfigMaps(namespace string) ([]corev1.ConfigMap, error) {
+	if c.hasSynced(cacheConfigMaps) {
+		objs, err := c.factory.Core().V1().ConfigMaps().Lister().ConfigMaps(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheConfigMaps, err)
+	}
+	list, err := c.client.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListSecrets 获取Secret列表
+func (c *clusterCache) ListSecrets(namespace string) ([]corev1.Secret, error) {
+	if c.hasSynced(cacheSecrets) {
+		objs, err := c.factory.Core().V1().Secrets().Lister().Secrets(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheSecrets, err)
+	}
+	list, err := c.client.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListPvcs 获取PVC列表
+func (c *clusterCache) ListPvcs(namespace string) ([]corev1.PersistentVolumeClaim, error) {
+	if c.hasSynced(cachePvcs) {
+		objs, err := c.factory.Core().V1().PersistentVolumeClaims().Lister().PersistentVolumeClaims(namespace).List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cachePvcs, err)
+	}
+	list, err := c.client.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListPvs 获取PV列表
+func (c *clusterCache) ListPvs() ([]corev1.PersistentVolume, error) {
+	if c.hasSynced(cachePvs) {
+		objs, err := c.factory.Core().V1().PersistentVolumes().Lister().List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cachePvs, err)
+	}
+	list, err := c.client.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListNodes 获取Node列表
+func (c *clusterCache) ListNodes() ([]corev1.Node, error) {
+	if c.hasSynced(cacheNodes) {
+		objs, err := c.factory.Core().V1().Nodes().Lister().List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheNodes, err)
+	}
+	list, err := c.client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListNamespaces 获取Namespace列表
+func (c *clusterCache) ListNamespaces() ([]corev1.Namespace, error) {
+	if c.hasSynced(cacheNamespaces) {
+		objs, err := c.factory.Core().V1().Namespaces().Lister().List(labels.Everything())
+		if err == nil {
+			return copyItems(objs), nil
+		}
+		c.logListerError(cacheNamespaces, err)
+	}
+	list, err := c.client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// 以下为滚动更新监听等需要反复查询单个资源的方法，缓存中没有时回退到apiserver，刚创建的资源可能还没有同步到缓存
+// 返回的是缓存中对象的指针，调用方只读
+
+// GetDeployment 获取Deployment
+func (c *clusterCache) GetDeployment(namespace, name string) (*appsv1.Deployment, error) {
+	if c.hasSynced(cacheDeployments) {
+		if obj, err := c.factory.Apps().V1().Deployments().Lister().Deployments(namespace).Get(name); err == nil {
+			return obj, nil
+		}
+	}
+	return c.client.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// GetStatefulSet 获取StatefulSet
+func (c *clusterCache) GetStatefulSet(namespace, name string) (*appsv1.StatefulSet, error) {
+	if c.hasSynced(cacheStatefulSets) {
+		if obj, err := c.factory.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).Get(name); err == nil {
+			return obj, nil
+		}
+	}
+	return c.client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// GetDaemonSet 获取DaemonSet
+func (c *clusterCache) GetDaemonSet(namespace, name string) (*appsv1.DaemonSet, error) {
+	if c.hasSynced(cacheDaemonSets) {
+		if obj, err := c.factory.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).Get(name); err == nil {
+			return obj, nil
+		}
+	}
+	return c.client.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// ListPodsBySelector 获取namespace下标签匹配selector的Pod
+func (c *clusterCache) ListPodsBySelector(namespace string, selector labels.Selector) ([]*corev1.Pod, error) {
+	if c.hasSynced(cachePods) {
+		objs, err := c.factory.Core().V1().Pods().Lister().Pods(namespace).List(selector)
+		if err == nil {
+			return objs, nil
+		}
+		c.logListerError(cachePods, err)
+	}
+	list, err := c.client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, len(list.Items))
+	for i := range list.Items {
+		pods[i] = &list.Items[i]
+	}
+	return pods, nil
+}
+
+// logListerError lister读取失败时记录日志，随后回退到直接请求apiserver
+func (c *clusterCache) logListerError(resource string, err error) {
+	zap.L().Warn(fmt.Sprintf("从informer缓存获取%s失败, 回退到apiserver, %v", resource, err), zap.String("cluster", c.cluster))
+}
+
+// copyItems 将lister返回的指针列表拷贝成值列表
+func copyItems[T any](objs []*T) []T {
+	items := make([]T, len(objs))
+	for i := range objs {
+		items[i] = *objs[i]
+	}
+	return items
+}
\ No newline at end of file
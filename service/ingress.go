@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	nwv1 "k8s.io/api/networking/v1"
@@ -19,6 +17,7 @@ type ingress struct{}
 type IngressResp struct {
 	Total int            `json:"total"`
 	Items []nwv1.Ingress `json:"items"`
+	PageInfo
 }
 
 // IngressCreate 用于创建ingress需要的参数属性的定义
@@ -46,23 +45,26 @@ type IngressNp struct {
 }
 
 // GetIngress 获取Ingress列表
-func (i *ingress) GetIngress(client *kubernetes.Clientset, filterName, namespace string, limit, page int) (ingressRest *IngressResp, err error) {
+func (i *ingress) GetIngress(cc *ClusterClient, namespace string, query *DataSelectQuery) (ingressRest *IngressResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.NetworkingV1().Ingresses(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Ingress列表失败, %v", err.Error()))
+			return nil, newAPIError("获取Ingress列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(i.toCells(list.Items), list.ListMeta)
+		return &IngressResp{Total: total, Items: i.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取IngressList类型的Ingress列表
-	serviceList, err := client.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	serviceList, err := cc.Cache.ListIngresses(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Ingress列表失败, %v", err.Error()))
-		return nil, errors.New("获取Ingress列表失败," + err.Error())
+		return nil, newAPIError("获取Ingress列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: i.toCells(serviceList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: i.toCells(serviceList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -73,8 +75,9 @@ func (i *ingress) GetIngress(client *kubernetes.Clientset, filterName, namespace
 	ingressS := i.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	ingressRest = &IngressResp{
-		Total: total,
-		Items: ingressS,
+		Total:    total,
+		Items:    ingressS,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return ingressRest, nil
 }
@@ -84,7 +87,7 @@ func (i *ingress) GetIngressDetail(client *kubernetes.Clientset, ingressName, na
 	service, err = client.NetworkingV1().Ingresses(namespace).Get(context.TODO(), ingressName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Ingress详情失败, %v", err.Error()))
-		return nil, errors.New("获取Ingress详情失败, " + err.Error())
+		return nil, newAPIError("获取Ingress详情失败", err)
 	}
 	return service, nil
 }
@@ -147,7 +150,7 @@ func (i *ingress) CreateIngress(client *kubernetes.Clientset, data *IngressCreat
 	_, err = client.NetworkingV1().Ingresses(data.Namespace).Create(context.TODO(), ingressCreat, metav1.CreateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("创建Ingress失败, %v", err.Error()))
-		return errors.New("创建Ingress失败, " + err.Error())
+		return newAPIError("创建Ingress失败", err)
 	}
 	return nil
 }
@@ -157,7 +160,7 @@ func (i *ingress) DeleteIngress(client *kubernetes.Clientset, ingressName, names
 	err = client.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), ingressName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除Ingress失败, %v", err.Error()))
-		return errors.New("删除Ingress失败, " + err.Error())
+		return newAPIError("删除Ingress失败", err)
 	}
 	return nil
 }
@@ -165,15 +168,15 @@ func (i *ingress) DeleteIngress(client *kubernetes.Clientset, ingressName, names
 // UpdateIngress 更新Ingress
 func (i *ingress) UpdateIngress(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var ingressS = &nwv1.Ingress{}
-	err = json.Unmarshal([]byte(content), ingressS)
+	err = decodeContent(content, ingressS)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.NetworkingV1().Ingresses(namespace).Update(context.TODO(), ingressS, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新Ingress失败, %v", err.Error()))
-		return errors.New("更新Ingress失败, " + err.Error())
+		return newAPIError("更新Ingress失败", err)
 	}
 	return nil
 }
@@ -1,127 +1,397 @@
 package service
 
 import (
-	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"go.uber.org/zap"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8sManagerApi/config"
 	"k8sManagerApi/dao"
-	"k8sManagerApi/model"
-	"strconv"
+	"sort"
+	"sync"
+	"time"
 )
 
 // 用于初始化k8是clientset
 
 var K8s k8s
 
+// k8s 集群客户端注册表，集群可以在运行时增删，读写时需要加锁
 type k8s struct {
-	ClientMap   map[string]*kubernetes.Clientset
-	KubeConfMap map[string]string
+	clusters map[string]*ClusterClient
+	mu       sync.RWMutex
 }
 
-// GetClient 获取client对象
-func (k *k8s) GetClient(cluster string) (*kubernetes.Clientset, error) {
-	client, ok := k.ClientMap[cluster]
+// ClusterClient 单个集群的客户端集合，终端、helm及各资源的增删改查共用同一份配置
+type ClusterClient struct {
+	Name       string
+	RestConfig *rest.Config
+	ClientSet  *kubernetes.Clientset
+	Dynamic    dynamic.Interface
+	Discovery  discovery.CachedDiscoveryInterface
+	Mapper     meta.ResettableRESTMapper
+	// Cache informer缓存，列表接口优先从缓存读取
+	Cache *clusterCache
+	// helmGetter helm使用的RESTClientGetter，共用discovery缓存
+	helmGetter *restClientGetter
+	// stopCh 集群后台任务(informer缓存、event、node及pod监听、健康探测等)的停止信号，移除集群时关闭
+	stopCh chan struct{}
+	// refreshMu、lastRefresh 控制discovery缓存的刷新频率
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+// discoveryRefreshInterval 资源未命中时两次刷新discovery缓存的最小间隔，避免请求不存在的资源时反复全量拉取
+const discoveryRefreshInterval = 10 * time.Second
+
+// ingressProbeTimeout 创建client时确认Ingress API的超时时间
+const ingressProbeTimeout = 3 * time.Second
+
+// newClusterClient 根据rest.Config创建集群的各类client
+func newClusterClient(cluster string, conf *rest.Config) (*ClusterClient, error) {
+	clientSet, err := kubernetes.NewForConfig(conf)
+	if err != nil {
+		zap.L().Error("create k8s client failed", zap.String("cluster", cluster))
+		return nil, errors.New(fmt.Sprintf("集群%s: 创建K8s client失败, %v", cluster, err))
+	}
+	dynamicClient, err := dynamic.NewForConfig(conf)
+	if err != nil {
+		zap.L().Error("create k8s dynamic client failed", zap.String("cluster", cluster))
+		return nil, errors.New(fmt.Sprintf("集群%s: 创建K8s dynamic client失败, %v", cluster, err))
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(conf)
+	if err != nil {
+		zap.L().Error("create k8s discovery client failed", zap.String("cluster", cluster))
+		return nil, errors.New(fmt.Sprintf("集群%s: 创建K8s discovery client失败, %v", cluster, err))
+	}
+	cachedDiscovery := memory.NewMemCacheClient(discoveryClient)
+	cc := &ClusterClient{
+		Name:       cluster,
+		RestConfig: conf,
+		ClientSet:  clientSet,
+		Dynamic:    dynamicClient,
+		Discovery:  cachedDiscovery,
+		Mapper:     restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscovery),
+		stopCh:     make(chan struct{}),
+	}
+	// 低版本集群没有networking/v1的Ingress，不注册对应的informer
+	cc.Cache = newClusterCache(cluster, clientSet, probeIngress(cluster, conf))
+	cc.helmGetter = &restClientGetter{client: cc}
+	return cc, nil
+}
+
+// probeIngress 确认集群是否提供networking/v1的Ingress，无法确认时按支持处理
+// 在AddCluster中同步执行，使用单独的短超时请求，集群不可达时不阻塞启动、注册集群及配置热加载，也不触发discovery缓存刷新
+func probeIngress(cluster string, conf *rest.Config) bool {
+	probeConf := rest.CopyConfig(conf)
+	probeConf.Timeout = ingressProbeTimeout
+	client, err := discovery.NewDiscoveryClientForConfig(probeConf)
+	if err != nil {
+		zap.L().Warn(fmt.Sprintf("集群%s: 确认Ingress API失败, %v", cluster, err))
+		return true
+	}
+	resources, err := client.ServerResourcesForGroupVersion(IngressGVR.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	if err != nil {
+		zap.L().Warn(fmt.Sprintf("集群%s: 确认Ingress API失败, %v", cluster, err))
+		return true
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == IngressGVR.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshDiscovery discovery缓存或RESTMapper未命中时调用，清空缓存后下次查询重新拉取
+// 缓存只在启动时拉取一次，之后安装的CRD、API组需要刷新后才能识别，返回false表示距上次刷新太近，未刷新
+func (cc *ClusterClient) RefreshDiscovery() bool {
+	cc.refreshMu.Lock()
+	defer cc.refreshMu.Unlock()
+	if time.Since(cc.lastRefresh) < discoveryRefreshInterval {
+		return false
+	}
+	cc.invalidateDiscovery()
+	return true
+}
+
+// resetDiscovery 立即清空discovery缓存及RESTMapper，不受刷新间隔限制，确定集群的API发生变化时使用，如刚创建了CRD
+func (cc *ClusterClient) resetDiscovery() {
+	cc.refreshMu.Lock()
+	defer cc.refreshMu.Unlock()
+	cc.invalidateDiscovery()
+}
+
+// invalidateDiscovery 清空缓存并记录刷新时间，调用方需持有refreshMu
+func (cc *ClusterClient) invalidateDiscovery() {
+	cc.lastRefresh = time.Now()
+	// Reset会同时Invalidate底层的discovery缓存
+	cc.Mapper.Reset()
+	zap.L().Info("discovery cache invalidated", zap.String("cluster", cc.Name))
+}
+
+// GetClusterClient 获取集群的客户端集合
+func (k *k8s) GetClusterClient(cluster string) (*ClusterClient, error) {
+	cc, ok := k.getCluster(cluster)
 	if !ok {
 		zap.L().Error("cluster not found", zap.String("cluster", cluster))
 		return nil, errors.New(fmt.Sprintf("集群不存在: %s, 无法获取client", cluster))
 	}
-	return client, nil
+	// 集群已被探测任务标记为不可达时直接返回，避免请求挂起到超时
+	if err := ClusterHealth.Check(cluster); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// GetClusterClientFor 获取集群的客户端集合，并确认集群提供gvr对应的资源，不支持时返回ErrNotSupported
+func (k *k8s) GetClusterClientFor(cluster string, gvr schema.GroupVersionResource) (*ClusterClient, error) {
+	cc, err := k.GetClusterClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := Capability.CheckResource(cc, gvr); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// GetClient 获取client对象
+func (k *k8s) GetClient(cluster string) (*kubernetes.Clientset, error) {
+	cc, err := k.GetClusterClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cc.ClientSet, nil
+}
+
+// GetClusterConf 获取指定集群的rest.Config，返回副本，调用方可以放心修改
+func (k *k8s) GetClusterConf(cluster string) (*rest.Config, error) {
+	cc, err := k.GetClusterClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return rest.CopyConfig(cc.RestConfig), nil
 }
 
-// GetClusterConf 获取指定集群的配置文件
-func (k *k8s) GetClusterConf(cluster string) (clusterConf string) {
-	for _, conf := range config.Conf.KubeConfigs {
+// ListClusters 获取所有集群名，按名称排序
+func (k *k8s) ListClusters() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	list := make([]string, 0, len(k.clusters))
+	for key := range k.clusters {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// HasCluster 判断集群是否已注册，不论健康状态
+func (k *k8s) HasCluster(cluster string) bool {
+	_, ok := k.getCluster(cluster)
+	return ok
+}
+
+// IsStaticCluster 判断集群是否定义在配置文件中，配置文件中的集群不允许通过接口修改
+func (k *k8s) IsStaticCluster(cluster string) bool {
+	for _, conf := range config.Get().KubeConfigs {
 		if conf.Name == cluster {
-			clusterConf = conf.Path
-			return clusterConf
+			return true
 		}
 	}
-	return ""
+	return false
 }
 
-// AddClusterNodeInfo k8s client初始化完成后，获取该集群的node信息，并写入到数据库中
-func (k *k8s) AddClusterNodeInfo() (err error) {
-	// 循环每一个集群，获取node信息
-	for cluster, client := range k.ClientMap {
-		nodeList, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			zap.L().Warn(fmt.Sprintf("获取集群 %v 中的node列表失败, error: %v", cluster, err))
-			return nil
-		}
-		for _, node := range nodeList.Items {
-			var (
-				hostname string
-				ip       string
-				isMaster uint
-			)
-			// 获取hostname 和 ip 地址
-			for _, i := range node.Status.Addresses {
-				if i.Type == "InternalIP" {
-					ip = i.Address
-				}
-				if i.Type == "Hostname" {
-					hostname = i.Address
-				}
-			}
+// AddCluster 添加集群的client，并启动该集群的informer缓存、event、node及pod监听、健康探测任务
+func (k *k8s) AddCluster(cluster string, conf *rest.Config) (err error) {
+	cc, err := newClusterClient(cluster, conf)
+	if err != nil {
+		return err
+	}
 
-			// 判断是否是master节点，1 是master节点 0 为work节点
-			_, ok := node.Labels["node-role.kubernetes.io/master"]
-			if !ok {
-				isMaster = 0
-			} else {
-				isMaster = 1
-			}
+	k.mu.Lock()
+	if _, ok := k.clusters[cluster]; ok {
+		k.mu.Unlock()
+		return conflict(fmt.Sprintf("集群已存在: %s", cluster))
+	}
+	k.clusters[cluster] = cc
+	k.mu.Unlock()
 
-			// 整理数据
-			cpu, _ := strconv.Atoi(node.Status.Capacity.Cpu().String())
-			var nodeInfo = model.Node{
-				Cluster:        cluster,
-				HostName:       hostname,
-				IP:             ip,
-				Master:         isMaster,
-				CPU:            cpu,
-				Memory:         node.Status.Capacity.Memory().String(),
-				System:         node.Status.NodeInfo.OperatingSystem,
-				OsImage:        node.Status.NodeInfo.OSImage,
-				Arch:           node.Status.NodeInfo.Architecture,
-				KernelVersion:  node.Status.NodeInfo.KernelVersion,
-				KubeletVersion: node.Status.NodeInfo.KubeletVersion,
-			}
+	k.startCluster(cc)
+	zap.L().Info("create k8s client successfully", zap.String("cluster", cluster))
+	return nil
+}
+
+// ReplaceCluster 用新的配置重建已注册集群的client，新client创建成功后才替换并停止旧client的后台任务
+// 返回旧的rest.Config，调用方后续步骤失败时可以用它再次替换回去
+func (k *k8s) ReplaceCluster(cluster string, conf *rest.Config) (*rest.Config, error) {
+	cc, err := newClusterClient(cluster, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	old, ok := k.clusters[cluster]
+	if !ok {
+		k.mu.Unlock()
+		return nil, notFound(fmt.Sprintf("集群不存在: %s", cluster))
+	}
+	close(old.stopCh)
+	k.clusters[cluster] = cc
+	k.mu.Unlock()
+
+	k.startCluster(cc)
+	zap.L().Info("replace k8s client successfully", zap.String("cluster", cluster))
+	return old.RestConfig, nil
+}
+
+// startCluster 启动集群的后台任务，stopCh关闭时退出
+func (k *k8s) startCluster(cc *ClusterClient) {
+	// informer缓存，供列表接口使用
+	cc.Cache.start(cc.stopCh)
+	// event任务，用于监听event并写入数据库
+	go Event.WatchEventTask(cc.Name, cc.Cache.factory, cc.stopCh)
+	// node任务，用于同步节点信息到数据库并记录变更历史
+	go Node.WatchNodeTask(cc.Name, cc.Cache.factory, cc.stopCh)
+	// pod任务，用于记录pod的生命周期
+	go Pod.WatchPodTask(cc.Name, cc.Cache.factory, cc.stopCh)
+	// 健康探测任务，定时检查apiserver是否可达
+	go ClusterHealth.WatchHealthTask(cc.Name, cc.ClientSet, cc.stopCh)
+}
+
+// RemoveCluster 移除集群的client，并停止该集群的后台任务
+func (k *k8s) RemoveCluster(cluster string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	cc, ok := k.clusters[cluster]
+	if !ok {
+		return
+	}
+	close(cc.stopCh)
+	delete(k.clusters, cluster)
+	zap.L().Info("remove k8s client successfully", zap.String("cluster", cluster))
+}
+
+// OnConfigChange 配置文件中的集群发生变化时，同步增删或重建对应集群的client及后台任务
+func (k *k8s) OnConfigChange(oldConf, newConf *config.ServerConfig) {
+	oldClusters := make(map[string]*config.Kubeconfig, len(oldConf.KubeConfigs))
+	for _, cluster := range oldConf.KubeConfigs {
+		oldClusters[cluster.Name] = cluster
+	}
+	newClusters := make(map[string]*config.Kubeconfig, len(newConf.KubeConfigs))
+	for _, cluster := range newConf.KubeConfigs {
+		newClusters[cluster.Name] = cluster
+	}
+
+	// 配置文件中删除的集群
+	for name := range oldClusters {
+		if _, ok := newClusters[name]; !ok {
+			k.RemoveCluster(name)
+		}
+	}
+	// 新增或配置有变化的集群，之前加载失败的集群也在这里重试
+	for name, cluster := range newClusters {
+		_, exists := k.getCluster(name)
+		old, isStatic := oldClusters[name]
+		if exists && !isStatic {
+			zap.L().Error(fmt.Sprintf("集群%s: 已通过接口注册, 忽略配置文件中的同名集群", name))
+			continue
+		}
+		if exists && *old == *cluster {
+			continue
+		}
+		conf, err := buildRestConfig(cluster)
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("集群%s: 创建K8s 配置失败, %v", name, err))
+			continue
+		}
+		k.RemoveCluster(name)
+		if err := k.AddCluster(name, conf); err != nil {
+			zap.L().Error(err.Error())
+		}
+	}
+}
 
-			// 调用数据库写入数据库中
-			err := dao.Node.Add(&nodeInfo)
+// buildRestConfig 根据配置文件中的集群配置生成rest.Config，Init及配置热加载共用
+func buildRestConfig(cluster *config.Kubeconfig) (*rest.Config, error) {
+	switch {
+	case cluster.InCluster:
+		return rest.InClusterConfig()
+	case cluster.Server != "":
+		conf := &rest.Config{
+			Host:        cluster.Server,
+			BearerToken: cluster.Token,
+			TLSClientConfig: rest.TLSClientConfig{
+				Insecure: cluster.Insecure,
+			},
+		}
+		if cluster.CAData != "" {
+			caData, err := base64.StdEncoding.DecodeString(cluster.CAData)
 			if err != nil {
-				zap.L().Error(fmt.Sprintf("Error creating node, %v", err))
+				return nil, errors.New(fmt.Sprintf("caData base64解码失败, %v", err))
 			}
+			conf.TLSClientConfig.CAData = caData
 		}
+		return conf, nil
+	default:
+		// path为空时按KUBECONFIG环境变量、~/.kube/config的顺序加载
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = cluster.Path
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: cluster.Context}
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
 	}
-	zap.L().Info("Add cluster node info to database successfully.")
-	return nil
+}
+
+// getCluster 获取注册表中的集群，不检查健康状态
+func (k *k8s) getCluster(cluster string) (*ClusterClient, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	cc, ok := k.clusters[cluster]
+	return cc, ok
 }
 
 // Init 初始化
 func (k *k8s) Init() {
-	k.ClientMap = map[string]*kubernetes.Clientset{}
-	// 根据配置文件中的多个集群，循环进行初始化
-	for _, cluster := range config.Conf.KubeConfigs {
-		conf, err := clientcmd.BuildConfigFromFlags("", cluster.Path)
+	k.clusters = map[string]*ClusterClient{}
+	// 根据配置文件中的多个集群，循环进行初始化，单个集群配置有误时跳过，不影响其它集群
+	for _, cluster := range config.Get().KubeConfigs {
+		conf, err := buildRestConfig(cluster)
 		if err != nil {
-			zap.L().Error("create k8s client config failed", zap.String("cluster", cluster.Name))
-			panic(fmt.Sprintf("集群%s: 创建K8s 配置失败 %v", cluster.Name, cluster.Path))
+			zap.L().Error(fmt.Sprintf("集群%s: 创建K8s 配置失败, %v", cluster.Name, err))
+			continue
 		}
-		clientSet, err := kubernetes.NewForConfig(conf)
-		if err != nil {
-			zap.L().Error("create k8s client failed", zap.String("cluster", cluster.Name))
-			panic(fmt.Sprintf("集群%s: 创建K8s client失败 %v", cluster.Name, cluster.Path))
+		if err := k.AddCluster(cluster.Name, conf); err != nil {
+			zap.L().Error(err.Error())
 		}
-		k.ClientMap[cluster.Name] = clientSet
-		zap.L().Info("create k8s client successfully", zap.String("cluster", cluster.Name))
 	}
 
-	_ = k.AddClusterNodeInfo()
+	// 加载通过接口注册的集群
+	clusters, err := dao.Cluster.GetAll()
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("加载数据库中的集群失败, %v", err))
+	}
+	for _, cluster := range clusters {
+		// 数据库中的kubeconfig同样来自接口上传，加载前做相同的校验
+		conf, err := loadKubeConfig([]byte(cluster.KubeConfig))
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("集群%s: 解析kubeconfig失败, %v", cluster.Name, err))
+			continue
+		}
+		if err := k.AddCluster(cluster.Name, conf); err != nil {
+			zap.L().Error(err.Error())
+		}
+	}
 }
\ No newline at end of file
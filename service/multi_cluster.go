@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var MultiCluster multiCluster
+
+type multiCluster struct{}
+
+// 单个集群查询的超时时间，超时的集群记录为失败，不影响其它集群的结果
+const multiClusterTimeout = 10 * time.Second
+
+// MultiClusterResp 跨集群列表的返回内容，Errors为查询失败的集群及原因，部分集群失败时仍返回其它集群的数据
+type MultiClusterResp struct {
+	Total  int               `json:"total"`
+	Items  []*ClusterItem    `json:"items"`
+	Errors map[string]string `json:"errors"`
+	PageInfo
+}
+
+// ClusterItem 跨集群列表中的单个资源，Cluster为资源所在的集群
+type ClusterItem struct {
+	Cluster string   `json:"cluster"`
+	Item    DataCell `json:"item"`
+}
+
+// clusterCell 给DataCell附加集群名，合并后仍可使用dataSelector的过滤、排序、分页
+type clusterCell struct {
+	DataCell
+	Cluster string
+}
+
+// clusterListFunc 获取单个集群的资源列表，并转换成DataCell
+type clusterListFunc func(cc *ClusterClient) ([]DataCell, error)
+
+// clusterListResult 单个集群的查询结果
+type clusterListResult struct {
+	cluster string
+	cells   []DataCell
+	err     error
+}
+
+// List 并发查询所有集群，合并结果后统一过滤、排序、分页
+// 多个集群的continue token无法合并，跨集群列表只支持page分页，continue模式直接返回错误
+func (m *multiCluster) List(fn clusterListFunc, query *DataSelectQuery) (*MultiClusterResp, error) {
+	if query.ServerSide() {
+		return nil, badRequest("continue模式不支持跨集群")
+	}
+	clusters := K8s.ListClusters()
+	results := make([]*clusterListResult, len(clusters))
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster string) {
+			defer wg.Done()
+			results[i] = m.listCluster(cluster, fn)
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	resp := &MultiClusterResp{Items: []*ClusterItem{}, Errors: map[string]string{}, PageInfo: PageInfo{Mode: PaginateModePage}}
+	cells := make([]DataCell, 0)
+	for _, result := range results {
+		if result.err != nil {
+			zap.L().Warn(fmt.Sprintf("跨集群查询失败, %v", result.err), zap.String("cluster", result.cluster))
+			resp.Errors[result.cluster] = result.err.Error()
+			continue
+		}
+		for _, cell := range result.cells {
+			cells = append(cells, clusterCell{DataCell: cell, Cluster: result.cluster})
+		}
+	}
+
+	// 实例化dataSelector结构体，组装数据
+	selectableData := &dataSelector{
+		GenericDataList: cells,
+		DataSelect:      query,
+	}
+	// 先过滤
+	filtered := selectableData.Filter()
+	resp.Total = len(filtered.GenericDataList)
+	// 再排序和分页
+	data := filtered.Sort().Paginate()
+	for _, cell := range data.GenericDataList {
+		c := cell.(clusterCell)
+		resp.Items = append(resp.Items, &ClusterItem{Cluster: c.Cluster, Item: c.DataCell})
+	}
+	return resp, nil
+}
+
+// listCluster 查询单个集群，集群不可达或超时时返回错误
+func (m *multiCluster) listCluster(cluster string, fn clusterListFunc) *clusterListResult {
+	cc, err := K8s.GetClusterClient(cluster)
+	if err != nil {
+		return &clusterListResult{cluster: cluster, err: err}
+	}
+	// 缓冲为1，超时返回后查询协程仍可写入并退出
+	ch := make(chan *clusterListResult, 1)
+	go func() {
+		cells, err := fn(cc)
+		ch <- &clusterListResult{cluster: cluster, cells: cells, err: err}
+	}()
+	select {
+	case result := <-ch:
+		return result
+	case <-time.After(multiClusterTimeout):
+		return &clusterListResult{cluster: cluster, err: errors.New(fmt.Sprintf("查询超时, 超过%v", multiClusterTimeout))}
+	}
+}
\ No newline at end of file
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -18,6 +17,7 @@ type namespace struct{}
 type namespaceResp struct {
 	Total int                `json:"total"`
 	Items []corev1.Namespace `json:"items"`
+	PageInfo
 }
 
 type NamespaceCreate struct {
@@ -27,23 +27,26 @@ type NamespaceCreate struct {
 }
 
 // GetNamespaces 获取Namespace列表
-func (n *namespace) GetNamespaces(client *kubernetes.Clientset, filterName string, limit, page int) (namespaceRest *namespaceResp, err error) {
+func (n *namespace) GetNamespaces(cc *ClusterClient, query *DataSelectQuery) (namespaceRest *namespaceResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().Namespaces().List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取namespace列表失败, %v", err.Error()))
+			return nil, newAPIError("获取namespace列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(n.toCells(list.Items), list.ListMeta)
+		return &namespaceResp{Total: total, Items: n.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取NodeList类型的Node列表
-	namespaceList, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := cc.Cache.ListNamespaces()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取namespace列表失败, %v", err.Error()))
-		return nil, errors.New("获取namespace列表失败," + err.Error())
+		return nil, newAPIError("获取namespace列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: n.toCells(namespaceList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: n.toCells(namespaceList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -54,8 +57,9 @@ func (n *namespace) GetNamespaces(client *kubernetes.Clientset, filterName strin
 	namespaces := n.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	namespaceRest = &namespaceResp{
-		Total: total,
-		Items: namespaces,
+		Total:    total,
+		Items:    namespaces,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return namespaceRest, nil
 }
@@ -65,7 +69,7 @@ func (n *namespace) GetNamespaceDetail(client *kubernetes.Clientset, namespaceNa
 	namespace, err = client.CoreV1().Namespaces().Get(context.TODO(), namespaceName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Namespace详情失败, %v", err.Error()))
-		return nil, errors.New("获取Namespace详情失败, " + err.Error())
+		return nil, newAPIError("获取Namespace详情失败", err)
 	}
 	return namespace, nil
 }
@@ -85,7 +89,7 @@ func (n *namespace) CreateNamespace(client *kubernetes.Clientset, data *Namespac
 	_, err = client.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("创建namespace失败, %v", err.Error()))
-		return errors.New("创建namespace失败," + err.Error())
+		return newAPIError("创建namespace失败", err)
 	}
 	return nil
 }
@@ -95,7 +99,7 @@ func (n *namespace) DeleteNamespace(client *kubernetes.Clientset, namespaceName
 	err = client.CoreV1().Namespaces().Delete(context.TODO(), namespaceName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除Namespace失败, %v", err.Error()))
-		return errors.New("删除Namespace失败, " + err.Error())
+		return newAPIError("删除Namespace失败", err)
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -18,26 +17,30 @@ type node struct{}
 type nodeResp struct {
 	Total int           `json:"total"`
 	Items []corev1.Node `json:"items"`
+	PageInfo
 }
 
 // GetNodes 获取node列表
-func (n *node) GetNodes(client *kubernetes.Clientset, filterName string, limit, page int) (nodeRest *nodeResp, err error) {
+func (n *node) GetNodes(cc *ClusterClient, query *DataSelectQuery) (nodeRest *nodeResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().Nodes().List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Node列表失败, %v", err.Error()))
+			return nil, newAPIError("获取Node列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(n.toCells(list.Items), list.ListMeta)
+		return &nodeResp{Total: total, Items: n.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取NodeList类型的Node列表
-	nodeList, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	nodeList, err := cc.Cache.ListNodes()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取node列表失败, %v", err.Error()))
-		return nil, errors.New("获取node列表失败," + err.Error())
+		return nil, newAPIError("获取node列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: n.toCells(nodeList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: n.toCells(nodeList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -48,8 +51,9 @@ func (n *node) GetNodes(client *kubernetes.Clientset, filterName string, limit,
 	nodes := n.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	nodeRest = &nodeResp{
-		Total: total,
-		Items: nodes,
+		Total:    total,
+		Items:    nodes,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return nodeRest, nil
 }
@@ -59,7 +63,7 @@ func (n *node) GetNodeDetail(client *kubernetes.Clientset, nodeName string) (nod
 	node, err = client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Node详情失败, %v", err.Error()))
-		return nil, errors.New("获取Node详情失败, " + err.Error())
+		return nil, newAPIError("获取Node详情失败", err)
 	}
 
 	return node, nil
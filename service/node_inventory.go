@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8sManagerApi/dao"
+	"k8sManagerApi/model"
+	"strconv"
+	"time"
+)
+
+// WatchNodeTask informer监听node，将节点信息同步到数据库中，节点属性变化及上下线时记录变更历史
+func (n *node) WatchNodeTask(cluster string, informerFactory informers.SharedInformerFactory, stopCh chan struct{}) {
+	// 旧版本写入的数据中同一主机名可能存在多条记录，同步前先清理，清理失败不影响同步
+	_ = dao.Node.RemoveDuplicates(cluster)
+	informer := informerFactory.Core().V1().Nodes()
+	_, err := informer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				n.syncNode(cluster, obj.(*corev1.Node))
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				// kubelet定时上报状态会频繁触发更新，只有入库的字段变化时才写数据库
+				if toNodeModel(cluster, oldObj.(*corev1.Node)) == toNodeModel(cluster, newObj.(*corev1.Node)) {
+					return
+				}
+				n.syncNode(cluster, newObj.(*corev1.Node))
+			},
+			DeleteFunc: func(obj interface{}) {
+				// watch断开期间删除的对象会以DeletedFinalStateUnknown的形式通知
+				if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = unknown.Obj
+				}
+				node, ok := obj.(*corev1.Node)
+				if !ok {
+					return
+				}
+				n.removeNode(cluster, nodeHostName(node))
+			},
+		},
+	)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("添加node事件处理失败, %v", err), zap.String("cluster", cluster))
+		return
+	}
+	informerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.Informer().HasSynced) {
+		return
+	}
+	// 首次同步完成后，清理服务停止期间已经下线的节点
+	n.removeStaleNodes(cluster, informer.Lister())
+	<-stopCh
+	zap.L().Info("stop watch node task", zap.String("cluster", cluster))
+}
+
+// GetNodeHistories 获取node变更记录
+func (n *node) GetNodeHistories(cluster, hostName string, page, limit int) (histories *dao.NodeHistories, err error) {
+	data, err := dao.NodeHistory.GetHistories(cluster, hostName, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// syncNode 按集群及主机名新增或更新node，记录变化的字段
+func (n *node) syncNode(cluster string, k8sNode *corev1.Node) {
+	newNode := toNodeModel(cluster, k8sNode)
+	oldNode, has, err := dao.Node.HasNode(cluster, newNode.HostName)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	if !has {
+		if err := dao.Node.Add(&newNode); err != nil {
+			return
+		}
+		n.addHistory(&model.NodeHistory{Cluster: cluster, HostName: newNode.HostName, Action: model.NodeActionAdd, ChangeTime: &now})
+		return
+	}
+
+	histories := diffNode(oldNode, &newNode)
+	rejoined := oldNode.DeletedAt.Valid
+	if len(histories) == 0 && !rejoined {
+		return
+	}
+	newNode.ID = oldNode.ID
+	newNode.CreatedAt = oldNode.CreatedAt
+	if err := dao.Node.Update(&newNode); err != nil {
+		return
+	}
+	if rejoined {
+		n.addHistory(&model.NodeHistory{Cluster: cluster, HostName: newNode.HostName, Action: model.NodeActionAdd, ChangeTime: &now})
+	}
+	for _, history := range histories {
+		history.ChangeTime = &now
+		n.addHistory(history)
+	}
+}
+
+// removeNode 软删除node并记录变更，已删除的node不重复记录
+func (n *node) removeNode(cluster, hostName string) {
+	oldNode, has, err := dao.Node.HasNode(cluster, hostName)
+	if err != nil || !has || oldNode.DeletedAt.Valid {
+		return
+	}
+	if err := dao.Node.Delete(cluster, hostName); err != nil {
+		return
+	}
+	now := time.Now()
+	n.addHistory(&model.NodeHistory{Cluster: cluster, HostName: hostName, Action: model.NodeActionDelete, ChangeTime: &now})
+}
+
+// removeStaleNodes 删除数据库中存在、但集群中已经不存在的node
+func (n *node) removeStaleNodes(cluster string, lister listerscorev1.NodeLister) {
+	hostNames, err := dao.Node.GetHostNames(cluster)
+	if err != nil {
+		return
+	}
+	nodes, err := lister.List(labels.Everything())
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取node列表失败, %v", err), zap.String("cluster", cluster))
+		return
+	}
+	current := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		current[nodeHostName(node)] = true
+	}
+	for _, hostName := range hostNames {
+		if !current[hostName] {
+			n.removeNode(cluster, hostName)
+		}
+	}
+}
+
+// addHistory 写入变更记录，失败时dao中已记录日志
+func (n *node) addHistory(history *model.NodeHistory) {
+	_ = dao.NodeHistory.Add(history)
+}
+
+// toNodeModel 将k8s中的node转换成数据库中的node，只包含需要入库的字段
+func toNodeModel(cluster string, node *corev1.Node) model.Node {
+	var ip string
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			ip = address.Address
+		}
+	}
+	// 判断是否是master节点，1 是master节点 0 为work节点，新版本的k8s使用control-plane标签
+	var isMaster uint
+	_, master := node.Labels["node-role.kubernetes.io/master"]
+	_, controlPlane := node.Labels["node-role.kubernetes.io/control-plane"]
+	if master || controlPlane {
+		isMaster = 1
+	}
+	return model.Node{
+		Cluster:        cluster,
+		HostName:       nodeHostName(node),
+		IP:             ip,
+		Master:         isMaster,
+		CPU:            int(node.Status.Capacity.Cpu().Value()),
+		Memory:         node.Status.Capacity.Memory().String(),
+		System:         node.Status.NodeInfo.OperatingSystem,
+		OsImage:        node.Status.NodeInfo.OSImage,
+		Arch:           node.Status.NodeInfo.Architecture,
+		KernelVersion:  node.Status.NodeInfo.KernelVersion,
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+	}
+}
+
+// nodeHostName 获取node的主机名，没有上报Hostname地址时使用node名称
+func nodeHostName(node *corev1.Node) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeHostName {
+			return address.Address
+		}
+	}
+	return node.Name
+}
+
+// diffNode 对比新旧node，每个变化的字段生成一条变更记录
+func diffNode(oldNode, newNode *model.Node) (histories []*model.NodeHistory) {
+	fields := []struct {
+		name     string
+		oldValue string
+		newValue string
+	}{
+		{"ip", oldNode.IP, newNode.IP},
+		{"master", strconv.Itoa(int(oldNode.Master)), strconv.Itoa(int(newNode.Master))},
+		{"cpu", strconv.Itoa(oldNode.CPU), strconv.Itoa(newNode.CPU)},
+		{"memory", oldNode.Memory, newNode.Memory},
+		{"system", oldNode.System, newNode.System},
+		{"os_image", oldNode.OsImage, newNode.OsImage},
+		{"arch", oldNode.Arch, newNode.Arch},
+		{"kernel_version", oldNode.KernelVersion, newNode.KernelVersion},
+		{"kubelet_version", oldNode.KubeletVersion, newNode.KubeletVersion},
+	}
+	for _, field := range fields {
+		if field.oldValue == field.newValue {
+			continue
+		}
+		histories = append(histories, &model.NodeHistory{
+			Cluster:  newNode.Cluster,
+			HostName: newNode.HostName,
+			Action:   model.NodeActionUpdate,
+			Field:    field.name,
+			OldValue: field.oldValue,
+			NewValue: field.newValue,
+		})
+	}
+	return histories
+}
\ No newline at end of file
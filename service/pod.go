@@ -3,8 +3,6 @@ package service
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"io"
@@ -12,9 +10,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8sManagerApi/config"
-	"k8sManagerApi/dao"
-	"k8sManagerApi/model"
-	"time"
 )
 
 var Pod = &pod{}
@@ -26,6 +21,7 @@ type pod struct {
 type PodsResp struct {
 	Total int          `json:"total"`
 	Items []corev1.Pod `json:"items"`
+	PageInfo
 }
 
 // PodsNp 获取每个namespace中pod数量，返回数据的结构体
@@ -35,26 +31,27 @@ type PodsNp struct {
 }
 
 // GetPods 获取pod列表，支持过滤、排序、分页
-func (p *pod) GetPods(client *kubernetes.Clientset, filterName, namespace string, limit, page int) (podsRest *PodsResp, err error) {
-
-	// context.TODO()用于声明一个空的context上下文，用于List方法内设置这个请求的超时（源码），这里的常用用法
-	// metav1.ListOptions{}用于过滤List数据，如使用label，field等
-	// kubectl get services --all-namespaces --field-seletor metadata.namespace != default
-	podList, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+func (p *pod) GetPods(cc *ClusterClient, namespace string, query *DataSelectQuery) (podsRest *PodsResp, err error) {
+	// continue模式直接请求apiserver分页，不经过informer缓存
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().Pods(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Pod列表失败, %v", err.Error()))
+			return nil, newAPIError("获取Pod列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(p.toCells(list.Items), list.ListMeta)
+		return &PodsResp{Total: total, Items: p.fromCells(cells), PageInfo: pageInfo}, nil
+	}
+	// 优先从informer缓存获取，缓存未同步完成时直接请求apiserver
+	podList, err := cc.Cache.ListPods(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Pod列表失败, %v", err.Error()))
-		return nil, errors.New("获取Pod列表失败," + err.Error())
+		return nil, newAPIError("获取Pod列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: p.toCells(podList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: p.toCells(podList),
+		DataSelect:      query,
 	}
 
 	// 先过滤
@@ -74,25 +71,37 @@ func (p *pod) GetPods(client *kubernetes.Clientset, filterName, namespace string
 		}
 		// 原始数据
 		fmt.Println("原始数据：")
-		for _, pod := range podList.Items {
+		for _, pod := range podList {
 			fmt.Println(pod.Name, pod.CreationTimestamp.Time)
 		}
 	*/
 
 	// 拼接返回数据
 	podsRest = &PodsResp{
-		Total: total,
-		Items: pods,
+		Total:    total,
+		Items:    pods,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return podsRest, nil
 }
 
+// GetPodsAllClusters 跨集群获取Pod列表
+func (p *pod) GetPodsAllClusters(namespace string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		pods, err := cc.Cache.ListPods(namespace)
+		if err != nil {
+			return nil, err
+		}
+		return p.toCells(pods), nil
+	}, query)
+}
+
 // GetPodDetail 获取Pod详情
 func (p *pod) GetPodDetail(client *kubernetes.Clientset, podName, namespace string) (pod *corev1.Pod, err error) {
 	pod, err = client.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Pod详情失败, %v", err.Error()))
-		return nil, errors.New("获取Pod详情失败, " + err.Error())
+		return nil, newAPIError("获取Pod详情失败", err)
 	}
 	return pod, nil
 }
@@ -102,26 +111,23 @@ func (p *pod) DeletePod(client *kubernetes.Clientset, podName, namespace, cluste
 	err = client.CoreV1().Pods(namespace).Delete(context.TODO(), podName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除Pod详情失败, %v", err.Error()))
-		return errors.New("删除Pod详情失败, " + err.Error())
+		return newAPIError("删除Pod详情失败", err)
 	}
-	//// 删除数据库中的Pod信息
-	//delPod := model.PodInfo{Cluster: cluster, PodName: podName}
-	//_ = dao.PodInfo.Del(&delPod)
 	return nil
 }
 
 // UpdatePod 更新Pod
 func (p *pod) UpdatePod(client *kubernetes.Clientset, namespace, content string) (err error) {
 	pod := &corev1.Pod{}
-	err = json.Unmarshal([]byte(content), pod)
+	err = decodeContent(content, pod)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败, " + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.CoreV1().Pods(namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新Pod失败, %v", err.Error()))
-		return errors.New("更新Pod失败, " + err.Error())
+		return newAPIError("更新Pod失败", err)
 	}
 	return nil
 }
@@ -131,7 +137,7 @@ func (p *pod) GetPodContainer(client *kubernetes.Clientset, podName, namespace s
 	pod, err := client.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Pod详情失败, %v", err.Error()))
-		return nil, errors.New("获取Pod详情失败, " + err.Error())
+		return nil, newAPIError("获取Pod详情失败", err)
 	}
 	for _, container := range pod.Spec.Containers {
 		containers = append(containers, container.Name)
@@ -142,7 +148,7 @@ func (p *pod) GetPodContainer(client *kubernetes.Clientset, podName, namespace s
 // GetPodLog 获取容器的日志
 func (p *pod) GetPodLog(client *kubernetes.Clientset, containerName, podName, namespace string) (Log string, err error) {
 	// 设置日志的配置，容器名，获取的内容的配置
-	lineLimit := int64(config.Conf.PodLogLine)
+	lineLimit := int64(config.Get().PodLogLine)
 	option := &corev1.PodLogOptions{
 		Container: containerName,
 		TailLines: &lineLimit,
@@ -153,7 +159,7 @@ func (p *pod) GetPodLog(client *kubernetes.Clientset, containerName, podName, na
 	logs, err := req.Stream(context.TODO())
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Pod日志失败, %v", err.Error()))
-		return "", errors.New("获取Pod日志失败, " + err.Error())
+		return "", newAPIError("获取Pod日志失败", err)
 	}
 	defer logs.Close()
 	// 读取流中的数据，将response body 写入到缓冲区，目的是为了转换成string类型
@@ -161,30 +167,30 @@ func (p *pod) GetPodLog(client *kubernetes.Clientset, containerName, podName, na
 	_, err = io.Copy(buf, logs)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Pod日志失败, %v", err.Error()))
-		return "", errors.New("获取Pod日志失败, " + err.Error())
+		return "", newAPIError("获取Pod日志失败", err)
 	}
 	return buf.String(), nil
 }
 
 // GetPodNumPerNp 获取每个namespace的pod数量
-func (p *pod) GetPodNumPerNp(client *kubernetes.Clientset) (podsNps []*PodsNp, err error) {
+func (p *pod) GetPodNumPerNp(cc *ClusterClient) (podsNps []*PodsNp, err error) {
 	// 获取Namespace列表
-	namespaceList, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := cc.Cache.ListNamespaces()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Namespace列表失败, %v", err.Error()))
-		return nil, errors.New("获取Namespace列表失败, " + err.Error())
+		return nil, newAPIError("获取Namespace列表失败", err)
 	}
-	for _, namespace := range namespaceList.Items {
+	for _, namespace := range namespaceList {
 		// 获取pod列表
-		podList, err := client.CoreV1().Pods(namespace.Name).List(context.TODO(), metav1.ListOptions{})
+		podList, err := cc.Cache.ListPods(namespace.Name)
 		if err != nil {
 			zap.L().Error(fmt.Sprintf("获取Pod列表失败, %v", err.Error()))
-			return nil, errors.New("获取Pod列表失败, " + err.Error())
+			return nil, newAPIError("获取Pod列表失败", err)
 		}
 		// 组装数据
 		podsNp := &PodsNp{
 			Namespace: namespace.Name,
-			PodNum:    len(podList.Items),
+			PodNum:    len(podList),
 		}
 		// 添加数据到podsNps中
 		podsNps = append(podsNps, podsNp)
@@ -192,56 +198,6 @@ func (p *pod) GetPodNumPerNp(client *kubernetes.Clientset) (podsNps []*PodsNp, e
 	return podsNps, nil
 }
 
-// GetAllPodsInfo 获取集群的所有Pod信息进行入库操作
-func (p *pod) GetAllPodsInfo(client *kubernetes.Clientset, cluster string) {
-	// 获取所有的Pod信息
-	allPods, err := client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		zap.L().Error(fmt.Sprintf("获取所有Pod信息失败, %v", err.Error()))
-		//return nil, errors.New("获取Namespace列表失败, " + err.Error())
-	}
-	for _, pod := range allPods.Items {
-		cTime := pod.CreationTimestamp.Time.Format("2006-01-02 15:04:05")
-		createTime, _ := time.Parse("2006-01-02 15:04:05", cTime)
-		p := &model.PodInfo{
-			Cluster:      cluster,
-			PodName:      pod.Name,
-			HostIP:       pod.Status.HostIP,
-			PodIP:        pod.Status.PodIP,
-			Status:       string(pod.Status.Phase),
-			CreationTime: createTime,
-		}
-		err := dao.PodInfo.Add(p)
-		if err != nil {
-			zap.L().Error(fmt.Sprintf("向数据库中添加Pod失败, %v", err))
-		}
-	}
-}
-
-// DelPodByDeployment 根据Deployment删除数据库中对应的Pod
-func (p *pod) DelPodByDeployment(client *kubernetes.Clientset, cluster, deploymentName, namespace string) (err error) {
-	// 首先根据去获取对应的deployment
-	zap.L().Error(fmt.Sprintf("%v, %v", deploymentName, namespace))
-	deploy, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
-	if err != nil {
-		zap.L().Error(fmt.Sprintf("获取deployment失败, %v", err.Error()))
-		return
-	}
-	// 获取deployment的标签选择器
-	labelSelector := deploy.Spec.Selector
-	// 根据标签选择器获取对应的pod
-	podList, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(labelSelector)})
-	for _, pod := range podList.Items {
-		delPod := model.PodInfo{Cluster: cluster, PodName: pod.Name}
-		fmt.Println("delpod, ", delPod)
-		if err := dao.PodInfo.Del(&delPod); err != nil {
-			zap.L().Error(fmt.Sprintf("删除%v中的Pod失败, %v", deploymentName, err))
-			return errors.New("删除Deployment中的Pod失败")
-		}
-	}
-	return nil
-}
-
 // 类型转换的方法，corev1.Pod -> DataCell, DataCell -> corev1.Pod
 // toCells corev1.Pod -> DataCell
 func (p *pod) toCells(pods []corev1.Pod) []DataCell {
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8sManagerApi/dao"
+	"k8sManagerApi/model"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PodPhaseDeleted Pod被删除时记录的状态
+const PodPhaseDeleted = "Deleted"
+
+// WatchPodTask informer监听pod，记录pod的生命周期：调度节点、启动时间、状态变化、重启次数、终止原因及删除时间
+func (p *pod) WatchPodTask(cluster string, informerFactory informers.SharedInformerFactory, stopCh chan struct{}) {
+	// 启动前一次性加载集群中未删除的pod记录，事件处理时按uid在内存中查找，不再每个事件查询一次数据库
+	recorder, err := newPodRecorder(cluster)
+	if err != nil {
+		return
+	}
+	informer := informerFactory.Core().V1().Pods()
+	_, err = informer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				recorder.syncPod(obj.(*corev1.Pod))
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				// 只有记录的字段变化时才写数据库，避免status中其它字段的变化频繁触发
+				if podInfoEqual(toPodInfo(cluster, oldObj.(*corev1.Pod)), toPodInfo(cluster, newObj.(*corev1.Pod))) {
+					return
+				}
+				recorder.syncPod(newObj.(*corev1.Pod))
+			},
+			DeleteFunc: func(obj interface{}) {
+				// watch断开期间删除的对象会以DeletedFinalStateUnknown的形式通知
+				if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = unknown.Obj
+				}
+				pod, ok := obj.(*corev1.Pod)
+				if !ok {
+					return
+				}
+				recorder.deletePod(pod)
+			},
+		},
+	)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("添加pod事件处理失败, %v", err), zap.String("cluster", cluster))
+		return
+	}
+	informerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.Informer().HasSynced) {
+		return
+	}
+	// 首次同步完成后，补记服务停止期间已经删除的pod
+	recorder.markDeletedPods(informer.Lister())
+	<-stopCh
+	zap.L().Info("stop watch pod task", zap.String("cluster", cluster))
+}
+
+// GetPodHistories 获取Pod生命周期记录，为空的条件不参与过滤，startTime、endTime查询在该时间段内存活过的Pod
+func (p *pod) GetPodHistories(cluster, namespace, ownerKind, ownerName, nodeName string, startTime, endTime time.Time, page, limit int) (podInfos *dao.PodInfos, err error) {
+	query := &dao.PodInfoQuery{
+		Cluster:   cluster,
+		Namespace: namespace,
+		OwnerKind: ownerKind,
+		OwnerName: ownerName,
+		NodeName:  nodeName,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	data, err := dao.PodInfo.GetPodInfos(query, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetPodPhases 获取单个Pod的状态变化记录
+func (p *pod) GetPodPhases(cluster, uid string) (phases []*model.PodPhase, err error) {
+	data, err := dao.PodPhase.GetPodPhases(cluster, uid)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// podRecorder 单个集群的pod记录，缓存数据库中未删除的pod记录，key为uid
+type podRecorder struct {
+	cluster string
+	mu      sync.Mutex
+	pods    map[string]*model.PodInfo
+}
+
+// newPodRecorder 加载集群中未删除的pod记录
+func newPodRecorder(cluster string) (*podRecorder, error) {
+	podInfos, err := dao.PodInfo.GetAlivePods(cluster)
+	if err != nil {
+		return nil, err
+	}
+	pods := make(map[string]*model.PodInfo, len(podInfos))
+	for _, podInfo := range podInfos {
+		pods[podInfo.UID] = podInfo
+	}
+	return &podRecorder{cluster: cluster, pods: pods}, nil
+}
+
+// syncPod 按uid新增或更新pod记录，状态变化时写入状态变化记录
+func (r *podRecorder) syncPod(k8sPod *corev1.Pod) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	newPod := toPodInfo(r.cluster, k8sPod)
+	oldPod, has := r.pods[newPod.UID]
+	if !has {
+		if err := dao.PodInfo.Add(&newPod); err != nil {
+			return
+		}
+		r.pods[newPod.UID] = &newPod
+		r.addPhase(&newPod, "", newPod.Status, k8sPod.Status.Reason)
+		return
+	}
+	// 服务重启后informer会重新通知所有pod，与数据库中一致时不再更新
+	if podInfoEqual(*oldPod, newPod) {
+		return
+	}
+	newPod.ID = oldPod.ID
+	newPod.CreatedAt = oldPod.CreatedAt
+	if err := dao.PodInfo.Update(&newPod); err != nil {
+		return
+	}
+	r.pods[newPod.UID] = &newPod
+	if oldPod.Status != newPod.Status {
+		reason := k8sPod.Status.Reason
+		if reason == "" {
+			reason = newPod.TerminationReason
+		}
+		r.addPhase(&newPod, oldPod.Status, newPod.Status, reason)
+	}
+}
+
+// deletePod pod被删除时记录删除时间，没有记录的pod直接新增一条已删除的记录
+func (r *podRecorder) deletePod(k8sPod *corev1.Pod) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	newPod := toPodInfo(r.cluster, k8sPod)
+	if oldPod, has := r.pods[newPod.UID]; has {
+		newPod.ID = oldPod.ID
+		newPod.CreatedAt = oldPod.CreatedAt
+	}
+	now := time.Now()
+	newPod.DeletionTime = &now
+	if err := dao.PodInfo.Update(&newPod); err != nil {
+		return
+	}
+	delete(r.pods, newPod.UID)
+	r.addPhase(&newPod, newPod.Status, PodPhaseDeleted, newPod.TerminationReason)
+}
+
+// markDeletedPods 数据库中未删除、但集群中已经不存在的pod，补记删除时间
+func (r *podRecorder) markDeletedPods(lister listerscorev1.PodLister) {
+	pods, err := lister.List(labels.Everything())
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取pod列表失败, %v", err), zap.String("cluster", r.cluster))
+		return
+	}
+	current := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		current[string(pod.UID)] = true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for uid, podInfo := range r.pods {
+		if current[uid] {
+			continue
+		}
+		podInfo.DeletionTime = &now
+		if err := dao.PodInfo.Update(podInfo); err != nil {
+			continue
+		}
+		delete(r.pods, uid)
+		r.addPhase(podInfo, podInfo.Status, PodPhaseDeleted, "")
+	}
+}
+
+// addPhase 写入状态变化记录，失败时dao中已记录日志
+func (r *podRecorder) addPhase(podInfo *model.PodInfo, oldPhase, newPhase, reason string) {
+	now := time.Now()
+	_ = dao.PodPhase.Add(&model.PodPhase{
+		Cluster:        podInfo.Cluster,
+		Namespace:      podInfo.Namespace,
+		PodName:        podInfo.PodName,
+		UID:            podInfo.UID,
+		OldPhase:       oldPhase,
+		NewPhase:       newPhase,
+		Reason:         reason,
+		TransitionTime: &now,
+	})
+}
+
+// toPodInfo 将k8s中的pod转换成数据库中的pod记录，只包含需要入库的字段
+func toPodInfo(cluster string, pod *corev1.Pod) model.PodInfo {
+	ownerKind, ownerName := podOwner(pod)
+	var restartCount int
+	for _, status := range pod.Status.ContainerStatuses {
+		restartCount += int(status.RestartCount)
+	}
+	return model.PodInfo{
+		Cluster:           cluster,
+		Namespace:         pod.Namespace,
+		PodName:           pod.Name,
+		UID:               string(pod.UID),
+		OwnerKind:         ownerKind,
+		OwnerName:         ownerName,
+		NodeName:          pod.Spec.NodeName,
+		HostIP:            pod.Status.HostIP,
+		PodIP:             pod.Status.PodIP,
+		Status:            string(pod.Status.Phase),
+		RestartCount:      restartCount,
+		TerminationReason: podTerminationReason(pod),
+		CreationTime:      pod.CreationTimestamp.Time,
+		StartTime:         metaTimePtr(pod.Status.StartTime),
+	}
+}
+
+// podOwner 获取pod所属的工作负载，Deployment创建的pod通过ReplicaSet名称还原Deployment名称
+func podOwner(pod *corev1.Pod) (kind, name string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", ""
+	}
+	if owner.Kind == "ReplicaSet" {
+		// ReplicaSet的名称为 Deployment名称-pod-template-hash
+		if hash, ok := pod.Labels["pod-template-hash"]; ok && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// podTerminationReason 获取pod最近一次终止的原因，被驱逐等pod级别的原因优先
+func podTerminationReason(pod *corev1.Pod) string {
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.Reason != "" {
+			return status.State.Terminated.Reason
+		}
+		if status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.Reason != "" {
+			return status.LastTerminationState.Terminated.Reason
+		}
+	}
+	return ""
+}
+
+// podInfoEqual 比较两条pod记录中由pod决定的字段，时间只比较到秒，与数据库中datetime的精度一致
+func podInfoEqual(a, b model.PodInfo) bool {
+	if a.Cluster != b.Cluster || a.Namespace != b.Namespace || a.PodName != b.PodName || a.UID != b.UID ||
+		a.OwnerKind != b.OwnerKind || a.OwnerName != b.OwnerName || a.NodeName != b.NodeName ||
+		a.HostIP != b.HostIP || a.PodIP != b.PodIP || a.Status != b.Status ||
+		a.RestartCount != b.RestartCount || a.TerminationReason != b.TerminationReason {
+		return false
+	}
+	if a.StartTime == nil || b.StartTime == nil {
+		return a.StartTime == b.StartTime
+	}
+	return a.StartTime.Unix() == b.StartTime.Unix()
+}
+
+// metaTimePtr metav1.Time -> *time.Time，为空时返回nil
+func metaTimePtr(t *metav1.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
\ No newline at end of file
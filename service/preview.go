@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+var Preview preview
+
+type preview struct{}
+
+// 字段变化的类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// 预览中的提示类型，immutable为修改了不可变字段，rollout为修改会触发滚动更新
+const (
+	WarningImmutable = "immutable"
+	WarningRollout   = "rollout"
+)
+
+// PreviewResp 预览的返回内容，Items与content中资源的顺序一致
+type PreviewResp struct {
+	Items []*PreviewResult `json:"items"`
+}
+
+// PreviewResult 单个资源的dry run结果，Valid为false时Error为apiserver拒绝的原因，此时Changes为提交内容与当前资源的差异
+type PreviewResult struct {
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Exists    bool              `json:"exists"` // 资源不存在时按创建预览
+	Valid     bool              `json:"valid"`
+	Error     string            `json:"error,omitempty"`
+	Changes   []*FieldChange    `json:"changes"`
+	Diff      string            `json:"diff"` // unified diff
+	Warnings  []*PreviewWarning `json:"warnings"`
+}
+
+// FieldChange 单个字段的变化，Path如spec.template.spec.containers[0].image
+type FieldChange struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// PreviewWarning 预览中需要注意的修改
+type PreviewWarning struct {
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// immutableFields 各资源创建后不能修改的字段，apiserver的报错不一定指明字段，提前对比给出提示
+var immutableFields = map[string][]string{
+	"Deployment":            {"spec.selector"},
+	"ReplicaSet":            {"spec.selector"},
+	"DaemonSet":             {"spec.selector"},
+	"StatefulSet":           {"spec.selector", "spec.serviceName", "spec.volumeClaimTemplates", "spec.podManagementPolicy"},
+	"Job":                   {"spec.selector", "spec.template"},
+	"Service":               {"spec.clusterIP", "spec.clusterIPs"},
+	"PersistentVolumeClaim": {"spec.accessModes", "spec.storageClassName", "spec.volumeMode", "spec.selector"},
+}
+
+// rolloutKinds 修改spec.template会触发滚动更新的资源
+var rolloutKinds = map[string]bool{
+	"Deployment":  true,
+	"DaemonSet":   true,
+	"StatefulSet": true,
+}
+
+// Preview 以DryRun: All提交content中的资源，返回当前资源与dry run结果的差异，不会修改集群中的资源
+// namespace为未指定namespace的资源使用的默认值，为空时使用default
+func (p *preview) Preview(cc *ClusterClient, namespace, content string) (*PreviewResp, error) {
+	objs, err := decodeObjects(content)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
+		return nil, badRequest("反序列化失败, " + err.Error())
+	}
+	// 与apply一致，内容及请求中都没有namespace时使用default
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	resp := &PreviewResp{Items: make([]*PreviewResult, 0, len(objs))}
+	for _, obj := range objs {
+		result, err := p.previewObject(cc, obj, namespace)
+		if err != nil {
+			return nil, err
+		}
+		resp.Items = append(resp.Items, result)
+	}
+	return resp, nil
+}
+
+// previewObject 预览单个资源，资源存在时dry run更新，不存在时dry run创建
+func (p *preview) previewObject(cc *ClusterClient, obj *unstructured.Unstructured, namespace string) (*PreviewResult, error) {
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+		return nil, badRequest("content中的资源缺少apiVersion、kind或metadata.name")
+	}
+	mapping, err := Generic.ResolveKind(cc, obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if !mapping.Namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	result := &PreviewResult{
+		Kind:      mapping.Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Changes:   []*FieldChange{},
+		Warnings:  []*PreviewWarning{},
+	}
+
+	client := Generic.client(cc, mapping, obj.GetNamespace())
+	live, err := client.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		zap.L().Error(fmt.Sprintf("获取%s详情失败, %v", mapping.Kind, err.Error()))
+		return nil, newAPIError(fmt.Sprintf("获取%s详情失败", mapping.Kind), err)
+	}
+	result.Exists = err == nil
+	var dryRun *unstructured.Unstructured
+	if result.Exists {
+		dryRun, err = client.Update(context.TODO(), obj, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	} else {
+		live = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		dryRun, err = client.Create(context.TODO(), obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	}
+	// dry run失败时对比提交的内容，便于定位被拒绝的字段
+	after, dryRunErr := obj, err
+	if dryRunErr == nil {
+		result.Valid = true
+		after = dryRun
+	} else {
+		result.Error = dryRunErr.Error()
+	}
+
+	before, err := p.cleanMap(live)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := p.cleanMap(after)
+	if err != nil {
+		return nil, err
+	}
+	diffValues("", before, afterMap, &result.Changes)
+	if result.Diff, err = unifiedDiff(before, afterMap); err != nil {
+		return nil, err
+	}
+	if result.Exists {
+		result.Warnings = p.warnings(mapping.Kind, live, result, dryRunErr)
+	}
+	return result, nil
+}
+
+// cleanMap 去掉集群维护的字段及resourceVersion，只保留用户关心的内容
+func (p *preview) cleanMap(obj *unstructured.Unstructured) (map[string]interface{}, error) {
+	if len(obj.Object) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	data, err := toCleanMap(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(data, "metadata", "resourceVersion")
+	return data, nil
+}
+
+// warnings 根据字段变化及dry run的报错给出不可变字段及滚动更新的提示
+func (p *preview) warnings(kind string, live *unstructured.Unstructured, result *PreviewResult, dryRunErr error) []*PreviewWarning {
+	warnings := make([]*PreviewWarning, 0)
+	seen := map[string]bool{}
+	add := func(typ, path, message string) {
+		if seen[typ+path] {
+			return
+		}
+		seen[typ+path] = true
+		warnings = append(warnings, &PreviewWarning{Type: typ, Path: path, Message: message})
+	}
+
+	immutable := immutableFields[kind]
+	// immutable为true的ConfigMap、Secret只能删除重建
+	if (kind == "ConfigMap" || kind == "Secret") && live.Object["immutable"] == true {
+		immutable = []string{"data", "binaryData", "stringData"}
+	}
+	for _, change := range result.Changes {
+		// 提交的内容可能省略了有默认值的字段，dry run失败时删除的字段不作为修改不可变字段的依据
+		if !result.Valid && change.Type == ChangeRemoved {
+			continue
+		}
+		for _, field := range immutable {
+			if hasPathPrefix(change.Path, field) {
+				add(WarningImmutable, field, fmt.Sprintf("%s的%s创建后不能修改, 需要删除后重建", kind, field))
+			}
+		}
+		if result.Valid && rolloutKinds[kind] && hasPathPrefix(change.Path, "spec.template") {
+			add(WarningRollout, "spec.template", fmt.Sprintf("修改了%s的Pod模板, 将触发滚动更新", kind))
+		}
+	}
+
+	// apiserver返回的字段级错误中明确指出的不可变字段
+	var status apierrors.APIStatus
+	if errors.As(dryRunErr, &status) && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if strings.Contains(cause.Message, "immutable") {
+				add(WarningImmutable, cause.Field, cause.Message)
+			}
+		}
+	}
+	return warnings
+}
+
+// diffValues 递归对比两个值，记录每个叶子字段的变化，列表按下标对比
+func diffValues(path string, before, after interface{}, changes *[]*FieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for k := range beforeMap {
+			keys = append(keys, k)
+		}
+		for k := range afterMap {
+			if _, ok := beforeMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			b, inBefore := beforeMap[k]
+			a, inAfter := afterMap[k]
+			switch {
+			case !inBefore:
+				*changes = append(*changes, &FieldChange{Path: childPath, Type: ChangeAdded, New: a})
+			case !inAfter:
+				*changes = append(*changes, &FieldChange{Path: childPath, Type: ChangeRemoved, Old: b})
+			default:
+				diffValues(childPath, b, a, changes)
+			}
+		}
+		return
+	}
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(beforeList):
+				*changes = append(*changes, &FieldChange{Path: childPath, Type: ChangeAdded, New: afterList[i]})
+			case i >= len(afterList):
+				*changes = append(*changes, &FieldChange{Path: childPath, Type: ChangeRemoved, Old: beforeList[i]})
+			default:
+				diffValues(childPath, beforeList[i], afterList[i], changes)
+			}
+		}
+		return
+	}
+	if !equality.Semantic.DeepEqual(before, after) {
+		*changes = append(*changes, &FieldChange{Path: path, Type: ChangeChanged, Old: before, New: after})
+	}
+}
+
+// unifiedDiff 将两个对象转换成yaml后生成unified diff，没有变化时为空
+func unifiedDiff(before, after map[string]interface{}) (string, error) {
+	beforeYAML, err := yaml.Marshal(before)
+	if err != nil {
+		return "", newAPIError("转换yaml失败", err)
+	}
+	afterYAML, err := yaml.Marshal(after)
+	if err != nil {
+		return "", newAPIError("转换yaml失败", err)
+	}
+	if len(before) == 0 {
+		beforeYAML = nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(beforeYAML)),
+		B:        difflib.SplitLines(string(afterYAML)),
+		FromFile: "live",
+		ToFile:   "dry-run",
+		Context:  3,
+	})
+}
+
+// hasPathPrefix 判断字段路径是否为prefix或其子字段
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
\ No newline at end of file
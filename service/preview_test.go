@@ -0,0 +1,91 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   []FieldChange
+	}{
+		{
+			name:   "equal",
+			before: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			after:  map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			want:   []FieldChange{},
+		},
+		{
+			name:   "changed scalar",
+			before: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			after:  map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(3)}},
+			want:   []FieldChange{{Path: "spec.replicas", Type: ChangeChanged, Old: int64(2), New: int64(3)}},
+		},
+		{
+			name:   "added and removed keys sorted",
+			before: map[string]interface{}{"b": "1", "c": "3"},
+			after:  map[string]interface{}{"a": "0", "b": "1"},
+			want: []FieldChange{
+				{Path: "a", Type: ChangeAdded, New: "0"},
+				{Path: "c", Type: ChangeRemoved, Old: "3"},
+			},
+		},
+		{
+			name:   "list items",
+			before: map[string]interface{}{"args": []interface{}{"-v", "--port=80"}},
+			after:  map[string]interface{}{"args": []interface{}{"-v", "--port=8080", "--debug"}},
+			want: []FieldChange{
+				{Path: "args[1]", Type: ChangeChanged, Old: "--port=80", New: "--port=8080"},
+				{Path: "args[2]", Type: ChangeAdded, New: "--debug"},
+			},
+		},
+		{
+			name:   "list shrinks",
+			before: []interface{}{"a", "b"},
+			after:  []interface{}{"a"},
+			want:   []FieldChange{{Path: "[1]", Type: ChangeRemoved, Old: "b"}},
+		},
+		{
+			name:   "type changed",
+			before: map[string]interface{}{"value": map[string]interface{}{"k": "v"}},
+			after:  map[string]interface{}{"value": "v"},
+			want:   []FieldChange{{Path: "value", Type: ChangeChanged, Old: map[string]interface{}{"k": "v"}, New: "v"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := []*FieldChange{}
+			diffValues("", tt.before, tt.after, &changes)
+			got := make([]FieldChange, 0, len(changes))
+			for _, change := range changes {
+				got = append(got, *change)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffValues() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	tests := []struct {
+		path   string
+		prefix string
+		want   bool
+	}{
+		{path: "spec.selector", prefix: "spec.selector", want: true},
+		{path: "spec.selector.matchLabels.app", prefix: "spec.selector", want: true},
+		{path: "spec.clusterIPs[0]", prefix: "spec.clusterIPs", want: true},
+		{path: "spec.clusterIPs", prefix: "spec.clusterIP", want: false},
+		{path: "spec.templateHash", prefix: "spec.template", want: false},
+		{path: "spec", prefix: "spec.selector", want: false},
+	}
+	for _, tt := range tests {
+		if got := hasPathPrefix(tt.path, tt.prefix); got != tt.want {
+			t.Errorf("hasPathPrefix(%q, %q) = %v, want %v", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
\ No newline at end of file
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -18,26 +17,30 @@ type pv struct{}
 type pvResp struct {
 	Total int                       `json:"total"`
 	Items []corev1.PersistentVolume `json:"items"`
+	PageInfo
 }
 
 // GetPvs 获取pv列表
-func (p *pv) GetPvs(client *kubernetes.Clientset, filterName string, limit, page int) (pvRest *pvResp, err error) {
+func (p *pv) GetPvs(cc *ClusterClient, query *DataSelectQuery) (pvRest *pvResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().PersistentVolumes().List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取pv列表失败, %v", err.Error()))
+			return nil, newAPIError("获取pv列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(p.toCells(list.Items), list.ListMeta)
+		return &pvResp{Total: total, Items: p.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取NodeList类型的Node列表
-	pvList, err := client.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	pvList, err := cc.Cache.ListPvs()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取pv列表失败, %v", err.Error()))
-		return nil, errors.New("获取pv列表失败," + err.Error())
+		return nil, newAPIError("获取pv列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: p.toCells(pvList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: p.toCells(pvList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -48,8 +51,9 @@ func (p *pv) GetPvs(client *kubernetes.Clientset, filterName string, limit, page
 	pvs := p.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	pvRest = &pvResp{
-		Total: total,
-		Items: pvs,
+		Total:    total,
+		Items:    pvs,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return pvRest, nil
 }
@@ -59,7 +63,7 @@ func (p *pv) GetPvDetail(client *kubernetes.Clientset, pvName string) (pv *corev
 	pv, err = client.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取pv详情失败, %v", err.Error()))
-		return nil, errors.New("获取pv详情失败, " + err.Error())
+		return nil, newAPIError("获取pv详情失败", err)
 	}
 	return pv, nil
 }
@@ -69,7 +73,7 @@ func (p *pv) DeletePv(client *kubernetes.Clientset, pvName string) (err error) {
 	err = client.CoreV1().PersistentVolumes().Delete(context.TODO(), pvName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除pv失败, %v", err.Error()))
-		return errors.New("删除pv失败, " + err.Error())
+		return newAPIError("删除pv失败", err)
 	}
 	return nil
 }
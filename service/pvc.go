@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -18,34 +16,39 @@ type pvc struct{}
 type PvcResp struct {
 	Total int                            `json:"total"`
 	Items []corev1.PersistentVolumeClaim `json:"items"`
+	PageInfo
 }
 
 // GetPvcs 获取PVC列表
-func (p *pvc) GetPvcs(client *kubernetes.Clientset, namespace, filterName string, limit, page int) (PvcRest *PvcResp, err error) {
+func (p *pvc) GetPvcs(cc *ClusterClient, namespace string, query *DataSelectQuery) (PvcRest *PvcResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取pvc列表失败, %v", err.Error()))
+			return nil, newAPIError("获取pvc列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(p.toCells(list.Items), list.ListMeta)
+		return &PvcResp{Total: total, Items: p.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取PVC list
-	pvcList, err := client.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	pvcList, err := cc.Cache.ListPvcs(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取PVC列表失败, %v", err.Error()))
-		return nil, errors.New("获取PVC列表失败" + err.Error())
+		return nil, newAPIError("获取PVC列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: p.toCells(pvcList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: p.toCells(pvcList),
+		DataSelect:      query,
 	}
 	filtered := selectableData.Filter()
 	total := len(filtered.GenericDataList)
 	data := filtered.Sort().Paginate()
 	pvcs := p.fromCells(data.GenericDataList)
 	PvcRest = &PvcResp{
-		Total: total,
-		Items: pvcs,
+		Total:    total,
+		Items:    pvcs,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return PvcRest, err
 }
@@ -56,7 +59,7 @@ func (p *pvc) GetPvcDetail(client *kubernetes.Clientset, namespace, pvcName stri
 	Pvc, err = client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取PVC详情失败, %v", err.Error()))
-		return nil, errors.New("获取PVC详情失败" + err.Error())
+		return nil, newAPIError("获取PVC详情失败", err)
 	}
 	return Pvc, err
 }
@@ -64,15 +67,15 @@ func (p *pvc) GetPvcDetail(client *kubernetes.Clientset, namespace, pvcName stri
 // UpdatePvc 更新PVC
 func (p *pvc) UpdatePvc(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var pvc = &corev1.PersistentVolumeClaim{}
-	err = json.Unmarshal([]byte(content), pvc)
+	err = decodeContent(content, pvc)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新PVC失败, %v", err.Error()))
-		return errors.New("更新PVC失败" + err.Error())
+		return newAPIError("更新PVC失败", err)
 	}
 	return nil
 }
@@ -82,7 +85,7 @@ func (p *pvc) DeletePvc(client *kubernetes.Clientset, namespace, pvcName string)
 	err = client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), pvcName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除PVC失败, %v", err.Error()))
-		return errors.New("删除PVC失败" + err.Error())
+		return newAPIError("删除PVC失败", err)
 	}
 	return nil
 }
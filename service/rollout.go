@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strconv"
+	"time"
+)
+
+var Rollout rollout
+
+type rollout struct{}
+
+// 滚动更新进度的轮询间隔及默认、最大超时时间
+const (
+	rolloutPollInterval   = 2 * time.Second
+	RolloutDefaultTimeout = 10 * time.Minute
+	RolloutMaxTimeout     = time.Hour
+)
+
+// 推送的事件类型，done为最后一个事件，Result为最终结果
+const (
+	RolloutEventProgress   = "progress"
+	RolloutEventPodFailure = "pod_failure"
+	RolloutEventCondition  = "condition"
+	RolloutEventDone       = "done"
+)
+
+// 滚动更新的最终结果
+const (
+	RolloutSuccess = "success"
+	RolloutFailed  = "failed"
+	RolloutTimeout = "timeout"
+)
+
+// podFailureReasons 新Pod处于这些状态时认为启动失败
+var podFailureReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+	"OOMKilled":                  true,
+	"Error":                      true,
+	"Unschedulable":              true,
+}
+
+// RolloutEvent 推送给前端的滚动更新进度
+type RolloutEvent struct {
+	Type       string              `json:"type"`
+	Kind       string              `json:"kind"`
+	Namespace  string              `json:"namespace"`
+	Name       string              `json:"name"`
+	Desired    int32               `json:"desired"`
+	Updated    int32               `json:"updated"`
+	Ready      int32               `json:"ready"`
+	Available  int32               `json:"available"`
+	Message    string              `json:"message"`
+	Pod        string              `json:"pod,omitempty"`        // pod_failure事件的Pod
+	Conditions []*RolloutCondition `json:"conditions,omitempty"` // condition事件中变化的condition
+	Result     string              `json:"result,omitempty"`     // done事件的结果
+	Time       time.Time           `json:"time"`
+}
+
+// RolloutCondition 工作负载的condition
+type RolloutCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// rolloutState 单次查询得到的工作负载状态
+type rolloutState struct {
+	desired, updated, ready, available int32
+	done, failed                       bool
+	message                            string
+	revision                           string // 版本变化时重新判断新Pod，Deployment为revision注解，DaemonSet为observedGeneration
+	generation                         int64  // 工作负载的generation，用于判断informer缓存是否落后
+	selector                           *metav1.LabelSelector
+	conditions                         []*RolloutCondition
+	// newPod 判断Pod是否属于本次更新的版本
+	newPod func(pod *corev1.Pod) bool
+}
+
+// Watch 监听Deployment、StatefulSet、DaemonSet的滚动更新，状态变化时推送事件，成功、失败、超时或ctx取消后关闭channel
+// 工作负载不存在或kind不支持时直接返回错误，不启动监听
+func (r *rollout) Watch(ctx context.Context, cc *ClusterClient, kind, namespace, name string, timeout time.Duration) (<-chan *RolloutEvent, error) {
+	if kind != KindDeployment && kind != KindStatefulSet && kind != KindDaemonSet {
+		return nil, Workload.kindError(kind)
+	}
+	if timeout <= 0 {
+		timeout = RolloutDefaultTimeout
+	}
+	if timeout > RolloutMaxTimeout {
+		timeout = RolloutMaxTimeout
+	}
+	state, err := r.state(ctx, cc, kind, namespace, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan *RolloutEvent)
+	go r.watch(ctx, cc, kind, namespace, name, timeout, state, events)
+	return events, nil
+}
+
+// watch 轮询工作负载及其Pod，只在进度、condition变化或出现新的Pod失败时推送，工作负载被删除时以失败结束
+func (r *rollout) watch(ctx context.Context, cc *ClusterClient, kind, namespace, name string, timeout time.Duration, state *rolloutState, events chan<- *RolloutEvent) {
+	defer close(events)
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(rolloutPollInterval)
+	defer ticker.Stop()
+
+	send := func(event *RolloutEvent) bool {
+		event.Kind, event.Namespace, event.Name, event.Time = kind, namespace, name, time.Now()
+		event.Desired, event.Updated, event.Ready, event.Available = state.desired, state.updated, state.ready, state.available
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	var last *rolloutState
+	reported := map[string]bool{}
+	for {
+		if last == nil || state.message != last.message || state.updated != last.updated || state.ready != last.ready || state.available != last.available {
+			if !send(&RolloutEvent{Type: RolloutEventProgress, Message: state.message}) {
+				return
+			}
+		}
+		if changed := changedConditions(last, state); len(changed) > 0 {
+			if !send(&RolloutEvent{Type: RolloutEventCondition, Conditions: changed, Message: "condition变化"}) {
+				return
+			}
+		}
+		for pod, message := range r.podFailures(cc, namespace, state) {
+			if reported[pod+message] {
+				continue
+			}
+			reported[pod+message] = true
+			if !send(&RolloutEvent{Type: RolloutEventPodFailure, Pod: pod, Message: message}) {
+				return
+			}
+		}
+		switch {
+		case state.failed:
+			send(&RolloutEvent{Type: RolloutEventDone, Result: RolloutFailed, Message: state.message})
+			return
+		case state.done:
+			send(&RolloutEvent{Type: RolloutEventDone, Result: RolloutSuccess, Message: state.message})
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			send(&RolloutEvent{Type: RolloutEventDone, Result: RolloutTimeout, Message: fmt.Sprintf("超过%v未完成滚动更新", timeout)})
+			return
+		case <-ticker.C:
+		}
+		next, err := r.state(ctx, cc, kind, namespace, name, state)
+		if apierrors.IsNotFound(err) {
+			send(&RolloutEvent{Type: RolloutEventDone, Result: RolloutFailed, Message: fmt.Sprintf("%s %s已被删除", kind, name)})
+			return
+		}
+		if err != nil {
+			zap.L().Warn(fmt.Sprintf("获取%s状态失败, %v", kind, err), zap.String("cluster", cc.Name))
+			continue
+		}
+		last, state = state, next
+	}
+}
+
+// state 获取工作负载当前的滚动更新状态，判断逻辑与kubectl rollout status一致
+// 工作负载及Pod从informer缓存中读取，只有版本变化时才请求apiserver获取新版本Pod的标签
+func (r *rollout) state(ctx context.Context, cc *ClusterClient, kind, namespace, name string, prev *rolloutState) (*rolloutState, error) {
+	apps := cc.ClientSet.AppsV1()
+	switch kind {
+	case KindDeployment:
+		deploy, err := latest(prev, func() (*appsv1.Deployment, error) {
+			return cc.Cache.GetDeployment(namespace, name)
+		}, func() (*appsv1.Deployment, error) {
+			return apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		})
+		if err != nil {
+			return nil, newAPIError("获取Deployment详情失败", err)
+		}
+		state := r.deploymentState(deploy)
+		if prev != nil && prev.newPod != nil && prev.revision == state.revision {
+			state.newPod = prev.newPod
+		} else {
+			state.newPod = r.deploymentNewPod(cc, deploy, state.revision)
+		}
+		return state, nil
+	case KindStatefulSet:
+		sts, err := latest(prev, func() (*appsv1.StatefulSet, error) {
+			return cc.Cache.GetStatefulSet(namespace, name)
+		}, func() (*appsv1.StatefulSet, error) {
+			return apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		})
+		if err != nil {
+			return nil, newAPIError("获取StatefulSet详情失败", err)
+		}
+		return r.statefulSetState(sts), nil
+	default:
+		ds, err := latest(prev, func() (*appsv1.DaemonSet, error) {
+			return cc.Cache.GetDaemonSet(namespace, name)
+		}, func() (*appsv1.DaemonSet, error) {
+			return apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		})
+		if err != nil {
+			return nil, newAPIError("获取DaemonSet详情失败", err)
+		}
+		state := r.daemonSetState(ds)
+		if prev != nil && prev.newPod != nil && prev.revision == state.revision {
+			state.newPod = prev.newPod
+		} else {
+			state.newPod = r.daemonSetNewPod(ctx, cc, ds)
+		}
+		return state, nil
+	}
+}
+
+// latest 获取工作负载，首次从apiserver读取，刚提交的更新可能还没同步到informer缓存，缓存中还是更新前已完成的状态
+// 之后从缓存读取，缓存中的generation小于上次读取到的时说明缓存落后，改为从apiserver读取
+func latest[T metav1.Object](prev *rolloutState, cached, live func() (T, error)) (T, error) {
+	if prev == nil {
+		return live()
+	}
+	obj, err := cached()
+	if err != nil || obj.GetGeneration() >= prev.generation {
+		return obj, err
+	}
+	return live()
+}
+
+// deploymentState Deployment的状态，controller处理了最新的generation后，超过progressDeadlineSeconds时为失败
+func (r *rollout) deploymentState(deploy *appsv1.Deployment) *rolloutState {
+	status := deploy.Status
+	state := &rolloutState{
+		desired:    desiredReplicas(deploy.Spec.Replicas),
+		updated:    status.UpdatedReplicas,
+		ready:      status.ReadyReplicas,
+		available:  status.AvailableReplicas,
+		revision:   deploy.Annotations[RevisionAnnotation],
+		selector:   deploy.Spec.Selector,
+		generation: deploy.Generation,
+	}
+	deadlineExceeded := false
+	for _, condition := range status.Conditions {
+		state.conditions = append(state.conditions, &RolloutCondition{Type: string(condition.Type), Status: string(condition.Status), Reason: condition.Reason, Message: condition.Message})
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			deadlineExceeded = true
+		}
+	}
+	switch {
+	// 新的更新还未被处理时，conditions仍是上一次更新的结果
+	case deploy.Generation > status.ObservedGeneration:
+		state.message = "等待Deployment的更新被controller处理"
+	case deadlineExceeded:
+		state.failed = true
+		state.message = fmt.Sprintf("Deployment %s超过progressDeadlineSeconds未完成滚动更新", deploy.Name)
+	case state.updated < state.desired:
+		state.message = fmt.Sprintf("%d/%d个副本已更新", state.updated, state.desired)
+	case status.Replicas > state.updated:
+		state.message = fmt.Sprintf("%d个旧副本等待终止", status.Replicas-state.updated)
+	case state.available < state.updated:
+		state.message = fmt.Sprintf("%d/%d个已更新的副本可用", state.available, state.updated)
+	default:
+		state.done = true
+		state.message = fmt.Sprintf("Deployment %s滚动更新完成", deploy.Name)
+	}
+	return state
+}
+
+// deploymentNewPod 根据当前版本ReplicaSet的pod-template-hash判断新Pod，ReplicaSet还未创建时返回nil
+func (r *rollout) deploymentNewPod(cc *ClusterClient, deploy *appsv1.Deployment, revision string) func(pod *corev1.Pod) bool {
+	_, replicaSets, err := Deployment.revisionReplicaSets(cc.ClientSet, deploy.Name, deploy.Namespace)
+	if err != nil {
+		return nil
+	}
+	hash := ""
+	for _, rs := range replicaSets {
+		if rs.Annotations[RevisionAnnotation] == revision {
+			hash = rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		}
+	}
+	if hash == "" {
+		return nil
+	}
+	return func(pod *corev1.Pod) bool {
+		return pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey] == hash
+	}
+}
+
+// statefulSetState StatefulSet的状态，设置了partition时只需要序号不小于partition的Pod完成更新
+func (r *rollout) statefulSetState(sts *appsv1.StatefulSet) *rolloutState {
+	status := sts.Status
+	state := &rolloutState{
+		desired:    desiredReplicas(sts.Spec.Replicas),
+		updated:    status.UpdatedReplicas,
+		ready:      status.ReadyReplicas,
+		available:  status.AvailableReplicas,
+		selector:   sts.Spec.Selector,
+		generation: sts.Generation,
+	}
+	for _, condition := range status.Conditions {
+		state.conditions = append(state.conditions, &RolloutCondition{Type: string(condition.Type), Status: string(condition.Status), Reason: condition.Reason, Message: condition.Message})
+	}
+	updateRevision := status.UpdateRevision
+	state.newPod = func(pod *corev1.Pod) bool {
+		return pod.Labels[appsv1.ControllerRevisionHashLabelKey] == updateRevision
+	}
+	var partition int32
+	if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		partition = *rollingUpdate.Partition
+	}
+	switch {
+	case sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType:
+		state.done = true
+		state.message = "OnDelete策略需要手动删除Pod完成更新"
+	case sts.Generation > status.ObservedGeneration:
+		state.message = "等待StatefulSet的更新被controller处理"
+	case state.ready < state.desired:
+		state.message = fmt.Sprintf("%d/%d个副本就绪", state.ready, state.desired)
+	case partition > 0 && state.updated < state.desired-partition:
+		state.message = fmt.Sprintf("partition为%d, %d/%d个副本已更新", partition, state.updated, state.desired-partition)
+	case partition == 0 && status.UpdateRevision != status.CurrentRevision:
+		state.message = fmt.Sprintf("%d/%d个副本已更新", state.updated, state.desired)
+	default:
+		state.done = true
+		state.message = fmt.Sprintf("StatefulSet %s滚动更新完成", sts.Name)
+	}
+	return state
+}
+
+// daemonSetState DaemonSet的状态，期望副本数为需要调度的节点数
+func (r *rollout) daemonSetState(ds *appsv1.DaemonSet) *rolloutState {
+	status := ds.Status
+	state := &rolloutState{
+		desired:    status.DesiredNumberScheduled,
+		updated:    status.UpdatedNumberScheduled,
+		ready:      status.NumberReady,
+		available:  status.NumberAvailable,
+		revision:   strconv.FormatInt(status.ObservedGeneration, 10),
+		selector:   ds.Spec.Selector,
+		generation: ds.Generation,
+	}
+	for _, condition := range status.Conditions {
+		state.conditions = append(state.conditions, &RolloutCondition{Type: string(condition.Type), Status: string(condition.Status), Reason: condition.Reason, Message: condition.Message})
+	}
+	switch {
+	case ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType:
+		state.done = true
+		state.message = "OnDelete策略需要手动删除Pod完成更新"
+	case ds.Generation > status.ObservedGeneration:
+		state.message = "等待DaemonSet的更新被controller处理"
+	case state.updated < state.desired:
+		state.message = fmt.Sprintf("%d/%d个节点已更新", state.updated, state.desired)
+	case state.available < state.desired:
+		state.message = fmt.Sprintf("%d/%d个节点可用", state.available, state.desired)
+	default:
+		state.done = true
+		state.message = fmt.Sprintf("DaemonSet %s滚动更新完成", ds.Name)
+	}
+	return state
+}
+
+// daemonSetNewPod DaemonSet的status中没有当前版本，以DaemonSet拥有的最新ControllerRevision的hash判断新Pod
+// revision为observedGeneration，controller处理了新的更新后重新获取
+func (r *rollout) daemonSetNewPod(ctx context.Context, cc *ClusterClient, ds *appsv1.DaemonSet) func(pod *corev1.Pod) bool {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil
+	}
+	list, err := cc.ClientSet.AppsV1().ControllerRevisions(ds.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		zap.L().Warn(fmt.Sprintf("获取ControllerRevision列表失败, %v", err), zap.String("cluster", cc.Name))
+		return nil
+	}
+	var newest *appsv1.ControllerRevision
+	for i := range list.Items {
+		revision := &list.Items[i]
+		if !metav1.IsControlledBy(revision, ds) {
+			continue
+		}
+		if newest == nil || revision.Revision > newest.Revision {
+			newest = revision
+		}
+	}
+	if newest == nil || newest.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] == "" {
+		return nil
+	}
+	hash := newest.Labels[appsv1.DefaultDaemonSetUniqueLabelKey]
+	return func(pod *corev1.Pod) bool {
+		return pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] == hash
+	}
+}
+
+// podFailures 获取新Pod的失败原因，key为Pod名
+func (r *rollout) podFailures(cc *ClusterClient, namespace string, state *rolloutState) map[string]string {
+	failures := map[string]string{}
+	if state.newPod == nil || state.selector == nil {
+		return failures
+	}
+	selector, err := metav1.LabelSelectorAsSelector(state.selector)
+	if err != nil {
+		return failures
+	}
+	pods, err := cc.Cache.ListPodsBySelector(namespace, selector)
+	if err != nil {
+		return failures
+	}
+	for _, pod := range pods {
+		if !state.newPod(pod) {
+			continue
+		}
+		if message := podFailure(pod); message != "" {
+			failures[pod.Name] = message
+		}
+	}
+	return failures
+}
+
+// podFailure 返回Pod的失败原因，正常时为空
+func podFailure(pod *corev1.Pod) string {
+	if pod.Status.Phase == corev1.PodFailed {
+		return fmt.Sprintf("Pod失败: %s %s", pod.Status.Reason, pod.Status.Message)
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && podFailureReasons[condition.Reason] {
+			return fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && podFailureReasons[waiting.Reason] {
+			return fmt.Sprintf("容器%s: %s %s", status.Name, waiting.Reason, waiting.Message)
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && podFailureReasons[terminated.Reason] && status.RestartCount > 0 {
+			return fmt.Sprintf("容器%s: %s, 退出码%d", status.Name, terminated.Reason, terminated.ExitCode)
+		}
+	}
+	return ""
+}
+
+// changedConditions 与上一次相比状态或原因变化的condition
+func changedConditions(prev, state *rolloutState) []*RolloutCondition {
+	old := map[string]*RolloutCondition{}
+	if prev != nil {
+		for _, condition := range prev.conditions {
+			old[condition.Type] = condition
+		}
+	}
+	changed := make([]*RolloutCondition, 0)
+	for _, condition := range state.conditions {
+		o, ok := old[condition.Type]
+		if !ok || o.Status != condition.Status || o.Reason != condition.Reason {
+			changed = append(changed, condition)
+		}
+	}
+	return changed
+}
\ No newline at end of file
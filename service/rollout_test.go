@@ -0,0 +1,216 @@
+package service
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestDeploymentState(t *testing.T) {
+	deadline := appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}
+	progressing := appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"}
+	available := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}
+	tests := []struct {
+		name           string
+		generation     int64
+		status         appsv1.DeploymentStatus
+		wantDone       bool
+		wantFailed     bool
+		wantMessage    string
+		wantConditions int
+	}{
+		{
+			name:           "new generation ignores stale deadline",
+			generation:     3,
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3, Conditions: []appsv1.DeploymentCondition{available, deadline}},
+			wantMessage:    "等待Deployment的更新被controller处理",
+			wantConditions: 2,
+		},
+		{
+			name:           "deadline exceeded keeps all conditions",
+			generation:     2,
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{deadline, available}},
+			wantFailed:     true,
+			wantMessage:    "Deployment web超过progressDeadlineSeconds未完成滚动更新",
+			wantConditions: 2,
+		},
+		{
+			name:           "updating replicas",
+			generation:     2,
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{progressing}},
+			wantMessage:    "1/3个副本已更新",
+			wantConditions: 1,
+		},
+		{
+			name:        "old replicas terminating",
+			generation:  2,
+			status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3},
+			wantMessage: "1个旧副本等待终止",
+		},
+		{
+			name:        "waiting for available",
+			generation:  2,
+			status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+			wantMessage: "2/3个已更新的副本可用",
+		},
+		{
+			name:           "done",
+			generation:     2,
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3, Conditions: []appsv1.DeploymentCondition{progressing, available}},
+			wantDone:       true,
+			wantMessage:    "Deployment web滚动更新完成",
+			wantConditions: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: tt.generation, Annotations: map[string]string{RevisionAnnotation: "5"}},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status:     tt.status,
+			}
+			state := Rollout.deploymentState(deploy)
+			if state.done != tt.wantDone || state.failed != tt.wantFailed {
+				t.Errorf("done, failed = %v, %v, want %v, %v", state.done, state.failed, tt.wantDone, tt.wantFailed)
+			}
+			if state.message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", state.message, tt.wantMessage)
+			}
+			if len(state.conditions) != tt.wantConditions {
+				t.Errorf("conditions = %v, want %v", len(state.conditions), tt.wantConditions)
+			}
+			if state.revision != "5" {
+				t.Errorf("revision = %q, want %q", state.revision, "5")
+			}
+		})
+	}
+}
+
+func TestStatefulSetState(t *testing.T) {
+	rollingUpdate := func(partition int32) appsv1.StatefulSetUpdateStrategy {
+		return appsv1.StatefulSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(partition)},
+		}
+	}
+	tests := []struct {
+		name        string
+		generation  int64
+		strategy    appsv1.StatefulSetUpdateStrategy
+		status      appsv1.StatefulSetStatus
+		wantDone    bool
+		wantMessage string
+	}{
+		{
+			name:        "on delete",
+			generation:  3,
+			strategy:    appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2},
+			wantDone:    true,
+			wantMessage: "OnDelete策略需要手动删除Pod完成更新",
+		},
+		{
+			name:        "generation not observed",
+			generation:  3,
+			strategy:    rollingUpdate(0),
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, CurrentRevision: "web-a", UpdateRevision: "web-a"},
+			wantMessage: "等待StatefulSet的更新被controller处理",
+		},
+		{
+			name:        "waiting for ready",
+			generation:  2,
+			strategy:    rollingUpdate(0),
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, CurrentRevision: "web-a", UpdateRevision: "web-b"},
+			wantMessage: "2/3个副本就绪",
+		},
+		{
+			name:        "partition pending",
+			generation:  2,
+			strategy:    rollingUpdate(1),
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "web-a", UpdateRevision: "web-b"},
+			wantMessage: "partition为1, 1/2个副本已更新",
+		},
+		{
+			name:        "partition done",
+			generation:  2,
+			strategy:    rollingUpdate(1),
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2, CurrentRevision: "web-a", UpdateRevision: "web-b"},
+			wantDone:    true,
+			wantMessage: "StatefulSet web滚动更新完成",
+		},
+		{
+			name:        "revision pending",
+			generation:  2,
+			strategy:    rollingUpdate(0),
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2, CurrentRevision: "web-a", UpdateRevision: "web-b"},
+			wantMessage: "2/3个副本已更新",
+		},
+		{
+			name:        "done",
+			generation:  2,
+			strategy:    rollingUpdate(0),
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "web-b", UpdateRevision: "web-b"},
+			wantDone:    true,
+			wantMessage: "StatefulSet web滚动更新完成",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: tt.generation},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3), UpdateStrategy: tt.strategy},
+				Status:     tt.status,
+			}
+			state := Rollout.statefulSetState(sts)
+			if state.done != tt.wantDone {
+				t.Errorf("done = %v, want %v", state.done, tt.wantDone)
+			}
+			if state.message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", state.message, tt.wantMessage)
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{appsv1.ControllerRevisionHashLabelKey: tt.status.UpdateRevision}}}
+			if !state.newPod(pod) {
+				t.Errorf("newPod() = false for pod of update revision %q", tt.status.UpdateRevision)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	deploy := func(generation int64) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Generation: generation}}
+	}
+	tests := []struct {
+		name     string
+		prev     *rolloutState
+		cached   int64
+		wantLive bool
+	}{
+		{name: "first read goes to apiserver", cached: 2, wantLive: true},
+		{name: "cache up to date", prev: &rolloutState{generation: 2}, cached: 2},
+		{name: "cache newer", prev: &rolloutState{generation: 2}, cached: 3},
+		{name: "cache behind", prev: &rolloutState{generation: 3}, cached: 2, wantLive: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := false
+			_, err := latest(tt.prev, func() (*appsv1.Deployment, error) {
+				return deploy(tt.cached), nil
+			}, func() (*appsv1.Deployment, error) {
+				live = true
+				return deploy(3), nil
+			})
+			if err != nil {
+				t.Fatalf("latest() error = %v", err)
+			}
+			if live != tt.wantLive {
+				t.Errorf("live = %v, want %v", live, tt.wantLive)
+			}
+		})
+	}
+}
\ No newline at end of file
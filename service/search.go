@@ -0,0 +1,337 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sort"
+	"strings"
+	"time"
+)
+
+var Search search
+
+type search struct{}
+
+// 全局搜索支持的资源类型
+const (
+	KindPod         = "Pod"
+	KindDeployment  = "Deployment"
+	KindDaemonSet   = "DaemonSet"
+	KindStatefulSet = "StatefulSet"
+	KindService     = "Service"
+	KindIngress     = "Ingress"
+	KindConfigMap   = "ConfigMap"
+	KindSecret      = "Secret"
+	KindPvc         = "PersistentVolumeClaim"
+	KindPv          = "PersistentVolume"
+	KindNode        = "Node"
+	KindNamespace   = "Namespace"
+)
+
+var searchKinds = []string{
+	KindPod, KindDeployment, KindDaemonSet, KindStatefulSet, KindService, KindIngress,
+	KindConfigMap, KindSecret, KindPvc, KindPv, KindNode, KindNamespace,
+}
+
+// 命中的字段
+const (
+	SearchFieldName       = "name"
+	SearchFieldImage      = "image"
+	SearchFieldHost       = "host"
+	SearchFieldLabel      = "label"
+	SearchFieldAnnotation = "annotation"
+)
+
+// SearchHit 搜索命中的资源，Field为命中的字段，Value为命中的值，如镜像名、label的key=value
+// 实现了DataCell，跨集群合并后可以使用dataSelector的过滤、排序、分页
+type SearchHit struct {
+	Kind         string            `json:"kind"`
+	Namespace    string            `json:"namespace"`
+	Name         string            `json:"name"`
+	Field        string            `json:"field"`
+	Value        string            `json:"value"`
+	Labels       map[string]string `json:"labels"`
+	CreationTime time.Time         `json:"creation_time"`
+}
+
+func (h SearchHit) GetCreation() time.Time {
+	return h.CreationTime
+}
+
+func (h SearchHit) GetName() string {
+	return h.Name
+}
+
+func (h SearchHit) GetNamespace() string {
+	return h.Namespace
+}
+
+func (h SearchHit) GetLabels() map[string]string {
+	return h.Labels
+}
+
+func (h SearchHit) GetFields() fields.Set {
+	return fields.Set{
+		"metadata.name":      h.Name,
+		"metadata.namespace": h.Namespace,
+		"kind":               h.Kind,
+	}
+}
+
+func (h SearchHit) GetStatus() string {
+	return ""
+}
+
+// searchObject 参与匹配的资源信息
+type searchObject struct {
+	meta   metav1.ObjectMeta
+	images []string
+	hosts  []string
+}
+
+// Search 在所有集群中搜索资源，keyword不区分大小写，匹配名称、镜像、ingress域名、label及annotation
+// kinds为空时搜索所有支持的资源，namespace不为空时只搜索该namespace下的资源
+func (s *search) Search(keyword, namespace string, kinds []string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	if strings.TrimSpace(keyword) == "" {
+		return nil, errors.New("搜索关键字不能为空")
+	}
+	if len(kinds) == 0 {
+		kinds = searchKinds
+	}
+	for _, kind := range kinds {
+		if !s.supportedKind(kind) {
+			return nil, errors.New(fmt.Sprintf("不支持搜索的资源类型: %s, 可选%s", kind, strings.Join(searchKinds, "、")))
+		}
+	}
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		return s.searchCluster(cc, keyword, namespace, kinds)
+	}, query)
+}
+
+// searchCluster 在单个集群中搜索，数据来自informer缓存
+func (s *search) searchCluster(cc *ClusterClient, keyword, namespace string, kinds []string) ([]DataCell, error) {
+	hits := make([]DataCell, 0)
+	for _, kind := range kinds {
+		objects, err := s.objects(cc, kind, namespace)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("获取%s列表失败, %v", kind, err))
+		}
+		for _, object := range objects {
+			if hit := matchObject(object, keyword); hit != nil {
+				hit.Kind = kind
+				hits = append(hits, *hit)
+			}
+		}
+	}
+	return hits, nil
+}
+
+// objects 获取单种资源的列表，指定namespace时跳过集群级别的资源，集群不支持的资源返回空
+func (s *search) objects(cc *ClusterClient, kind, namespace string) ([]searchObject, error) {
+	var objects []searchObject
+	switch kind {
+	case KindPod:
+		list, err := cc.Cache.ListPods(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta, images: podImages(&item.Spec)})
+		}
+	case KindDeployment:
+		list, err := cc.Cache.ListDeployments(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta, images: podImages(&item.Spec.Template.Spec)})
+		}
+	case KindDaemonSet:
+		list, err := cc.Cache.ListDaemonSets(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta, images: podImages(&item.Spec.Template.Spec)})
+		}
+	case KindStatefulSet:
+		list, err := cc.Cache.ListStatefulSets(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta, images: podImages(&item.Spec.Template.Spec)})
+		}
+	case KindService:
+		list, err := cc.Cache.ListServices(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	case KindIngress:
+		if ok, _ := Capability.IsSupported(cc, IngressGVR); !ok {
+			return nil, nil
+		}
+		list, err := cc.Cache.ListIngresses(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			object := searchObject{meta: item.ObjectMeta}
+			for _, rule := range item.Spec.Rules {
+				if rule.Host != "" {
+					object.hosts = append(object.hosts, rule.Host)
+				}
+			}
+			objects = append(objects, object)
+		}
+	case KindConfigMap:
+		list, err := cc.Cache.ListConfigMaps(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	case KindSecret:
+		list, err := cc.Cache.ListSecrets(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	case KindPvc:
+		list, err := cc.Cache.ListPvcs(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	case KindPv, KindNode, KindNamespace:
+		if namespace != "" {
+			return nil, nil
+		}
+		return s.clusterObjects(cc, kind)
+	}
+	return objects, nil
+}
+
+// clusterObjects 获取集群级别的资源列表
+func (s *search) clusterObjects(cc *ClusterClient, kind string) ([]searchObject, error) {
+	var objects []searchObject
+	switch kind {
+	case KindPv:
+		list, err := cc.Cache.ListPvs()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	case KindNode:
+		list, err := cc.Cache.ListNodes()
+		if err != nil {
+			return nil, err
+		}
+		// 不匹配status.images，其中是节点上缓存的镜像，不代表节点正在运行
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	case KindNamespace:
+		list, err := cc.Cache.ListNamespaces()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			objects = append(objects, searchObject{meta: item.ObjectMeta})
+		}
+	}
+	return objects, nil
+}
+
+// supportedKind 判断是否是支持搜索的资源类型
+func (s *search) supportedKind(kind string) bool {
+	for _, k := range searchKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// matchObject 按名称、镜像、域名、label、annotation的顺序匹配，返回第一个命中的字段，未命中返回nil
+func matchObject(object searchObject, keyword string) *SearchHit {
+	hit := &SearchHit{
+		Namespace:    object.meta.Namespace,
+		Name:         object.meta.Name,
+		Labels:       object.meta.Labels,
+		CreationTime: object.meta.CreationTimestamp.Time,
+	}
+	contains := func(value string) bool {
+		return strings.Contains(strings.ToLower(value), keyword)
+	}
+	if contains(object.meta.Name) {
+		hit.Field, hit.Value = SearchFieldName, object.meta.Name
+		return hit
+	}
+	for _, image := range object.images {
+		if contains(image) {
+			hit.Field, hit.Value = SearchFieldImage, image
+			return hit
+		}
+	}
+	for _, host := range object.hosts {
+		if contains(host) {
+			hit.Field, hit.Value = SearchFieldHost, host
+			return hit
+		}
+	}
+	// map的遍历顺序不固定，按key排序后匹配，多个key命中时每次返回同一个
+	for _, key := range sortedKeys(object.meta.Labels) {
+		if pair := key + "=" + object.meta.Labels[key]; contains(pair) {
+			hit.Field, hit.Value = SearchFieldLabel, pair
+			return hit
+		}
+	}
+	for _, key := range sortedKeys(object.meta.Annotations) {
+		// kubectl apply记录的完整配置中包含镜像等所有字段，匹配它没有意义
+		if key == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		if pair := key + "=" + object.meta.Annotations[key]; contains(pair) {
+			hit.Field, hit.Value = SearchFieldAnnotation, pair
+			return hit
+		}
+	}
+	return nil
+}
+
+// sortedKeys 按字母顺序返回map的key
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// podImages 获取pod中所有容器的镜像，包括init容器
+func podImages(spec *corev1.PodSpec) []string {
+	images := make([]string, 0, len(spec.InitContainers)+len(spec.Containers))
+	for _, container := range spec.InitContainers {
+		images = append(images, container.Image)
+	}
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
\ No newline at end of file
@@ -0,0 +1,43 @@
+package service
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestMatchObject(t *testing.T) {
+	object := searchObject{
+		meta: metav1.ObjectMeta{
+			Name:        "web",
+			Labels:      map[string]string{"tier": "frontend", "app": "frontend", "team": "frontend"},
+			Annotations: map[string]string{"owner": "frontend-team", "contact": "frontend@example.com"},
+		},
+		images: []string{"nginx:1.25"},
+	}
+	tests := []struct {
+		keyword   string
+		wantField string
+		wantValue string
+	}{
+		{keyword: "web", wantField: SearchFieldName, wantValue: "web"},
+		{keyword: "nginx", wantField: SearchFieldImage, wantValue: "nginx:1.25"},
+		{keyword: "frontend", wantField: SearchFieldLabel, wantValue: "app=frontend"},
+		{keyword: "example", wantField: SearchFieldAnnotation, wantValue: "contact=frontend@example.com"},
+		{keyword: "redis"},
+	}
+	for _, tt := range tests {
+		// 多次匹配结果应保持一致
+		for i := 0; i < 10; i++ {
+			hit := matchObject(object, tt.keyword)
+			if tt.wantField == "" {
+				if hit != nil {
+					t.Fatalf("matchObject(%q) = %+v, want nil", tt.keyword, hit)
+				}
+				continue
+			}
+			if hit == nil || hit.Field != tt.wantField || hit.Value != tt.wantValue {
+				t.Fatalf("matchObject(%q) = %+v, want %s %s", tt.keyword, hit, tt.wantField, tt.wantValue)
+			}
+		}
+	}
+}
\ No newline at end of file
@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -18,44 +16,60 @@ type secret struct{}
 type SecretResp struct {
 	Total int             `json:"total"`
 	Items []corev1.Secret `json:"items"`
+	PageInfo
 }
 
 // GetSecrets 获取Secret列表
-func (s *secret) GetSecrets(client *kubernetes.Clientset, namespaces, filterName string, limit, page int) (SecretRest *SecretResp, err error) {
+func (s *secret) GetSecrets(cc *ClusterClient, namespace string, query *DataSelectQuery) (SecretRest *SecretResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().Secrets(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Secret列表失败, %v", err.Error()))
+			return nil, newAPIError("获取Secret列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(s.toCells(list.Items), list.ListMeta)
+		return &SecretResp{Total: total, Items: s.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取Secret
-	SecretList, err := client.CoreV1().Secrets(namespaces).List(context.TODO(), metav1.ListOptions{})
+	SecretList, err := cc.Cache.ListSecrets(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Secret列表失败, %v", err.Error()))
-		return nil, errors.New("获取Secret列表失败," + err.Error())
+		return nil, newAPIError("获取Secret列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: s.toCells(SecretList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: s.toCells(SecretList),
+		DataSelect:      query,
 	}
 	filtered := selectableData.Filter()
 	total := len(filtered.GenericDataList)
 	data := filtered.Sort().Paginate()
 	Secrets := s.fromCells(data.GenericDataList)
 	SecretRest = &SecretResp{
-		Total: total,
-		Items: Secrets,
+		Total:    total,
+		Items:    Secrets,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return SecretRest, nil
 }
 
+// GetSecretsAllClusters 跨集群获取Secret列表
+func (s *secret) GetSecretsAllClusters(namespace string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		secrets, err := cc.Cache.ListSecrets(namespace)
+		if err != nil {
+			return nil, err
+		}
+		return s.toCells(secrets), nil
+	}, query)
+}
+
 // GetSecretDetail 获取Secret详情
 func (s *secret) GetSecretDetail(client *kubernetes.Clientset, namespace, SecretName string) (Secret *corev1.Secret, err error) {
 	Secret, err = client.CoreV1().Secrets(namespace).Get(context.TODO(), SecretName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Secret详情失败, %v", err.Error()))
-		return nil, errors.New("获取Secret详情失败, " + err.Error())
+		return nil, newAPIError("获取Secret详情失败", err)
 	}
 	return Secret, nil
 }
@@ -63,15 +77,15 @@ func (s *secret) GetSecretDetail(client *kubernetes.Clientset, namespace, Secret
 // UpdateSecret 更新Secret
 func (s *secret) UpdateSecret(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var Secret = &corev1.Secret{}
-	err = json.Unmarshal([]byte(content), Secret)
+	err = decodeContent(content, Secret)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.CoreV1().Secrets(namespace).Update(context.TODO(), Secret, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新Secret失败, %v", err.Error()))
-		return errors.New("更新Secret失败, " + err.Error())
+		return newAPIError("更新Secret失败", err)
 	}
 	return nil
 }
@@ -81,7 +95,7 @@ func (s *secret) DeleteSecret(client *kubernetes.Clientset, namespace, SecretNam
 	err = client.CoreV1().Secrets(namespace).Delete(context.TODO(), SecretName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除Secret失败, %v", err.Error()))
-		return errors.New("删除Secret失败, " + err.Error())
+		return newAPIError("删除Secret失败", err)
 	}
 	return nil
 }
@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -20,6 +18,7 @@ type service struct{}
 type ServicesResp struct {
 	Total int              `json:"total"`
 	Items []corev1.Service `json:"items"`
+	PageInfo
 }
 
 /*
@@ -59,23 +58,26 @@ type ServiceSNp struct {
 }
 
 // GetServices 获取Service列表
-func (s *service) GetServices(client *kubernetes.Clientset, filterName, namespace string, limit, page int) (serviceRest *ServicesResp, err error) {
+func (s *service) GetServices(cc *ClusterClient, namespace string, query *DataSelectQuery) (serviceRest *ServicesResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.CoreV1().Services(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Service列表失败, %v", err.Error()))
+			return nil, newAPIError("获取Service列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(s.toCells(list.Items), list.ListMeta)
+		return &ServicesResp{Total: total, Items: s.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取serviceList类型的service列表
-	serviceList, err := client.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	serviceList, err := cc.Cache.ListServices(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Service列表失败, %v", err.Error()))
-		return nil, errors.New("获取Service列表失败," + err.Error())
+		return nil, newAPIError("获取Service列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: s.toCells(serviceList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: s.toCells(serviceList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -86,8 +88,9 @@ func (s *service) GetServices(client *kubernetes.Clientset, filterName, namespac
 	services := s.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	serviceRest = &ServicesResp{
-		Total: total,
-		Items: services,
+		Total:    total,
+		Items:    services,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return serviceRest, nil
 }
@@ -97,7 +100,7 @@ func (s *service) GetServicesDetail(client *kubernetes.Clientset, serviceName, n
 	service, err = client.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Service详情失败, %v", err.Error()))
-		return nil, errors.New("获取Service详情失败, " + err.Error())
+		return nil, newAPIError("获取Service详情失败", err)
 	}
 	return service, nil
 }
@@ -141,7 +144,7 @@ func (s *service) CreateService(client *kubernetes.Clientset, data *ServiceCreat
 	_, err = client.CoreV1().Services(data.Namespace).Create(context.TODO(), serviced, metav1.CreateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("创建Service失败, %v", err.Error()))
-		return errors.New("创建Service失败, " + err.Error())
+		return newAPIError("创建Service失败", err)
 	}
 	return nil
 }
@@ -151,7 +154,7 @@ func (s *service) DeleteService(client *kubernetes.Clientset, serviceName, names
 	err = client.CoreV1().Services(namespace).Delete(context.TODO(), serviceName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除Service失败, %v", err.Error()))
-		return errors.New("删除Service失败, " + err.Error())
+		return newAPIError("删除Service失败", err)
 	}
 	return nil
 }
@@ -159,15 +162,15 @@ func (s *service) DeleteService(client *kubernetes.Clientset, serviceName, names
 // UpdateService 更新Service
 func (s *service) UpdateService(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var service = &corev1.Service{}
-	err = json.Unmarshal([]byte(content), service)
+	err = decodeContent(content, service)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.CoreV1().Services(namespace).Update(context.TODO(), service, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新Service失败, %v", err.Error()))
-		return errors.New("更新Service失败, " + err.Error())
+		return newAPIError("更新Service失败", err)
 	}
 	return nil
 }
@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 var StatefulSet statefulSet
@@ -19,6 +22,38 @@ type statefulSet struct{}
 type StatefulSetsResp struct {
 	Total int                  `json:"total"`
 	Items []appsv1.StatefulSet `json:"items"`
+	PageInfo
+}
+
+// StatefulSetCreate 定义StatefulSetCreate结构体，用于创建StatefulSet需要的参数属性的定义
+type StatefulSetCreate struct {
+	Name          string            `json:"name"`
+	Namespace     string            `json:"namespace"`
+	Replicas      int32             `json:"replicas"`
+	Image         string            `json:"image"`
+	Label         map[string]string `json:"label"`
+	Cpu           string            `json:"cpu"`
+	Memory        string            `json:"memory"`
+	ContainerPort int32             `json:"container_port"`
+	HealthCheck   bool              `json:"health_check"`
+	HealthPath    string            `json:"health_path"`
+	// ServiceName 管理Pod网络标识的headless Service，为空时与StatefulSet同名，不存在时自动创建
+	ServiceName string `json:"service_name"`
+	// PodManagementPolicy OrderedReady按序号逐个创建，Parallel并行创建，为空时为OrderedReady
+	PodManagementPolicy string `json:"pod_management_policy"`
+	// Partition 滚动更新时只更新序号不小于partition的Pod，用于分批发布
+	Partition            int32                  `json:"partition"`
+	VolumeClaimTemplates []*VolumeClaimTemplate `json:"volume_claim_templates"`
+	Cluster              string                 `json:"cluster"`
+}
+
+// VolumeClaimTemplate 每个Pod独立的PVC，PVC名为 模板名-StatefulSet名-序号
+type VolumeClaimTemplate struct {
+	Name         string   `json:"name"`
+	StorageClass string   `json:"storage_class"` // 为空时使用集群默认的StorageClass
+	Storage      string   `json:"storage"`       // 如10Gi
+	AccessModes  []string `json:"access_modes"`  // 为空时为ReadWriteOnce
+	MountPath    string   `json:"mount_path"`
 }
 
 // StatefulSetSNp 用于返回namespace中StatefulSet的数量
@@ -28,23 +63,26 @@ type StatefulSetSNp struct {
 }
 
 // GetStatefulSets 获取StatefulSet列表
-func (s *statefulSet) GetStatefulSets(client *kubernetes.Clientset, filterName, namespace string, limit, page int) (statefulSetRest *StatefulSetsResp, err error) {
+func (s *statefulSet) GetStatefulSets(cc *ClusterClient, namespace string, query *DataSelectQuery) (statefulSetRest *StatefulSetsResp, err error) {
+	if query.ServerSide() {
+		list, err := cc.ClientSet.AppsV1().StatefulSets(namespace).List(context.TODO(), query.ListOptions())
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取StatefulSet列表失败, %v", err.Error()))
+			return nil, newAPIError("获取StatefulSet列表失败", err)
+		}
+		cells, total, pageInfo := query.selectPage(s.toCells(list.Items), list.ListMeta)
+		return &StatefulSetsResp{Total: total, Items: s.fromCells(cells), PageInfo: pageInfo}, nil
+	}
 	// 获取StatefulSetList类型的Statefulset列表
-	statefulSetList, err := client.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	statefulSetList, err := cc.Cache.ListStatefulSets(namespace)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取StatefulSet列表失败, %v", err.Error()))
-		return nil, errors.New("获取StatefulSet列表失败," + err.Error())
+		return nil, newAPIError("获取StatefulSet列表失败", err)
 	}
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: s.toCells(statefulSetList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginate: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: s.toCells(statefulSetList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -55,18 +93,30 @@ func (s *statefulSet) GetStatefulSets(client *kubernetes.Clientset, filterName,
 	statefulSets := s.fromCells(data.GenericDataList)
 	// 拼接返回数据
 	statefulSetRest = &StatefulSetsResp{
-		Total: total,
-		Items: statefulSets,
+		Total:    total,
+		Items:    statefulSets,
+		PageInfo: PageInfo{Mode: PaginateModePage},
 	}
 	return statefulSetRest, nil
 }
 
+// GetStatefulSetsAllClusters 跨集群获取StatefulSet列表
+func (s *statefulSet) GetStatefulSetsAllClusters(namespace string, query *DataSelectQuery) (*MultiClusterResp, error) {
+	return MultiCluster.List(func(cc *ClusterClient) ([]DataCell, error) {
+		statefulSets, err := cc.Cache.ListStatefulSets(namespace)
+		if err != nil {
+			return nil, err
+		}
+		return s.toCells(statefulSets), nil
+	}, query)
+}
+
 // GetStatefulSetDetail 获取StatefulSet详情
 func (s *statefulSet) GetStatefulSetDetail(client *kubernetes.Clientset, statefulSetName, namespace string) (statefulset *appsv1.StatefulSet, err error) {
 	statefulset, err = client.AppsV1().StatefulSets(namespace).Get(context.TODO(), statefulSetName, metav1.GetOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取StatefulSet详情失败, %v", err.Error()))
-		return nil, errors.New("获取StatefulSet详情失败, " + err.Error())
+		return nil, newAPIError("获取StatefulSet详情失败", err)
 	}
 	return statefulset, nil
 }
@@ -76,46 +126,305 @@ func (s *statefulSet) DeleteStatefulSet(client *kubernetes.Clientset, statefulSe
 	err = client.AppsV1().StatefulSets(namespace).Delete(context.TODO(), statefulSetName, metav1.DeleteOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("删除StatefulSet失败, %v", err.Error()))
-		return errors.New("删除StatefulSet失败, " + err.Error())
+		return newAPIError("删除StatefulSet失败", err)
 	}
 	return nil
 }
 
+// RestartStatefulSet 重启StatefulSet，Pod按序号从大到小逐个重建
+func (s *statefulSet) RestartStatefulSet(client *kubernetes.Clientset, statefulSetName, namespace string) (err error) {
+	return Workload.Restart(client, KindStatefulSet, namespace, statefulSetName)
+}
+
 // UpdateStatefulSet 更新StatefulSet
 func (s *statefulSet) UpdateStatefulSet(client *kubernetes.Clientset, namespace, content string) (err error) {
 	var stateful = &appsv1.StatefulSet{}
-	err = json.Unmarshal([]byte(content), stateful)
+	err = decodeContent(content, stateful)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("反序列化失败, %v", err.Error()))
-		return errors.New("反序列化失败," + err.Error())
+		return badRequest("反序列化失败, " + err.Error())
 	}
 	_, err = client.AppsV1().StatefulSets(namespace).Update(context.TODO(), stateful, metav1.UpdateOptions{})
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("更新StatefulSet失败, %v", err.Error()))
-		return errors.New("更新StatefulSet失败, " + err.Error())
+		return newAPIError("更新StatefulSet失败", err)
+	}
+	return nil
+}
+
+// CreateStatefulSet 创建StatefulSet，headless Service不存在时一并创建，StatefulSet创建失败时删除新建的Service
+func (s *statefulSet) CreateStatefulSet(client *kubernetes.Clientset, data *StatefulSetCreate) (err error) {
+	sts, err := s.build(data)
+	if err != nil {
+		return err
+	}
+	created, err := s.ensureHeadlessService(client, data, sts.Spec.ServiceName)
+	if err != nil {
+		return err
+	}
+	if _, err = client.AppsV1().StatefulSets(data.Namespace).Create(context.TODO(), sts, metav1.CreateOptions{}); err != nil {
+		zap.L().Error(fmt.Sprintf("创建StatefulSet失败, %v", err.Error()))
+		if created {
+			if err := client.CoreV1().Services(data.Namespace).Delete(context.TODO(), sts.Spec.ServiceName, metav1.DeleteOptions{}); err != nil {
+				zap.L().Warn(fmt.Sprintf("删除headless Service失败, %v", err.Error()))
+			}
+		}
+		return newAPIError("创建StatefulSet失败", err)
 	}
 	return nil
 }
 
+// SetStatefulSetReplicas 设置StatefulSet副本数，缩容时从序号最大的Pod开始删除，PVC会保留
+func (s *statefulSet) SetStatefulSetReplicas(client *kubernetes.Clientset, statefulSetName, namespace string, replicas int32) (replica int32, err error) {
+	if replicas < 0 {
+		return 0, badRequest("副本数不能小于0")
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scale, err := client.AppsV1().StatefulSets(namespace).GetScale(context.TODO(), statefulSetName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		scale.Spec.Replicas = replicas
+		_, err = client.AppsV1().StatefulSets(namespace).UpdateScale(context.TODO(), statefulSetName, scale, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("更新StatefulSet副本数失败, %v", err.Error()))
+		return 0, newAPIError("更新StatefulSet副本数失败", err)
+	}
+	return replicas, nil
+}
+
+// SetStatefulSetPartition 修改滚动更新的partition，逐步调小partition即可按序号从大到小分批发布，为0时全部更新
+func (s *statefulSet) SetStatefulSetPartition(client *kubernetes.Clientset, statefulSetName, namespace string, partition int32) (err error) {
+	sts, err := client.AppsV1().StatefulSets(namespace).Get(context.TODO(), statefulSetName, metav1.GetOptions{})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("获取StatefulSet详情失败, %v", err.Error()))
+		return newAPIError("获取StatefulSet详情失败", err)
+	}
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return badRequest(fmt.Sprintf("StatefulSet %s的更新策略为OnDelete, 不支持partition", statefulSetName))
+	}
+	// 暂停时partition为副本数，修改后恢复时会被还原
+	if _, ok := sts.Annotations[PausedPartitionAnnotation]; ok {
+		return badRequest(fmt.Sprintf("StatefulSet %s已暂停, 恢复后才能修改partition", statefulSetName))
+	}
+	if partition < 0 || partition > desiredReplicas(sts.Spec.Replicas) {
+		return badRequest(fmt.Sprintf("partition需要在0到副本数%d之间", desiredReplicas(sts.Spec.Replicas)))
+	}
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"updateStrategy": map[string]interface{}{
+				"type":          appsv1.RollingUpdateStatefulSetStrategyType,
+				"rollingUpdate": map[string]interface{}{"partition": partition},
+			},
+		},
+	}
+	return Workload.patch(client, KindStatefulSet, namespace, statefulSetName, "修改partition", patch)
+}
+
+// build 根据StatefulSetCreate组装StatefulSet，并校验参数
+func (s *statefulSet) build(data *StatefulSetCreate) (*appsv1.StatefulSet, error) {
+	if data.Name == "" || data.Image == "" {
+		return nil, badRequest("名称及镜像不能为空")
+	}
+	// label同时作为selector，为空时apiserver会拒绝创建
+	if len(data.Label) == 0 {
+		return nil, badRequest("标签不能为空")
+	}
+	if data.HealthCheck && data.ContainerPort <= 0 {
+		return nil, badRequest("开启健康检查时容器端口不能为空")
+	}
+	if data.Replicas < 0 {
+		return nil, badRequest("副本数不能小于0")
+	}
+	if data.Partition < 0 || data.Partition > data.Replicas {
+		return nil, badRequest(fmt.Sprintf("partition需要在0到副本数%d之间", data.Replicas))
+	}
+	policy := appsv1.PodManagementPolicyType(data.PodManagementPolicy)
+	switch policy {
+	case "":
+		policy = appsv1.OrderedReadyPodManagement
+	case appsv1.OrderedReadyPodManagement, appsv1.ParallelPodManagement:
+	default:
+		return nil, badRequest(fmt.Sprintf("不支持的podManagementPolicy: %s, 可选OrderedReady、Parallel", data.PodManagementPolicy))
+	}
+	serviceName := data.ServiceName
+	if serviceName == "" {
+		serviceName = data.Name
+	}
+	container := corev1.Container{
+		Name:  data.Name,
+		Image: data.Image,
+	}
+	if data.ContainerPort > 0 {
+		container.Ports = []corev1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: data.ContainerPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		}
+	}
+	// 判断是否打开了检查功能
+	if data.HealthCheck {
+		handler := corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: data.HealthPath,
+				Port: intstr.FromInt(int(data.ContainerPort)),
+			},
+		}
+		container.ReadinessProbe = &corev1.Probe{ProbeHandler: handler, InitialDelaySeconds: 5, TimeoutSeconds: 5, PeriodSeconds: 5}
+		container.LivenessProbe = &corev1.Probe{ProbeHandler: handler, InitialDelaySeconds: 15, TimeoutSeconds: 5, PeriodSeconds: 5}
+	}
+	// 定义容器的limit和request资源，未填写时不限制
+	resources := corev1.ResourceList{}
+	for name, value := range map[corev1.ResourceName]string{corev1.ResourceCPU: data.Cpu, corev1.ResourceMemory: data.Memory} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, badRequest(fmt.Sprintf("%s格式错误: %s", name, value))
+		}
+		resources[name] = quantity
+	}
+	if len(resources) > 0 {
+		container.Resources = corev1.ResourceRequirements{Limits: resources, Requests: resources.DeepCopy()}
+	}
+
+	claims := make([]corev1.PersistentVolumeClaim, 0, len(data.VolumeClaimTemplates))
+	for _, template := range data.VolumeClaimTemplates {
+		claim, err := s.buildClaim(template)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, *claim)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: template.Name, MountPath: template.MountPath})
+	}
+
+	replicas, partition := data.Replicas, data.Partition
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: data.Namespace,
+			Labels:    data.Label,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         serviceName,
+			PodManagementPolicy: policy,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: data.Label,
+			},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: data.Label,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{container},
+				},
+			},
+			VolumeClaimTemplates: claims,
+		},
+	}, nil
+}
+
+// buildClaim 组装volumeClaimTemplate
+func (s *statefulSet) buildClaim(template *VolumeClaimTemplate) (*corev1.PersistentVolumeClaim, error) {
+	if template.Name == "" || template.MountPath == "" {
+		return nil, badRequest("volumeClaimTemplate的名称及挂载路径不能为空")
+	}
+	storage, err := resource.ParseQuantity(template.Storage)
+	if err != nil {
+		return nil, badRequest(fmt.Sprintf("volumeClaimTemplate %s的容量格式错误: %s", template.Name, template.Storage))
+	}
+	accessModes := make([]corev1.PersistentVolumeAccessMode, 0, len(template.AccessModes))
+	for _, mode := range template.AccessModes {
+		accessModes = append(accessModes, corev1.PersistentVolumeAccessMode(mode))
+	}
+	if len(accessModes) == 0 {
+		accessModes = append(accessModes, corev1.ReadWriteOnce)
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: template.Name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessModes,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: storage},
+			},
+		},
+	}
+	if template.StorageClass != "" {
+		claim.Spec.StorageClassName = &template.StorageClass
+	}
+	return claim, nil
+}
+
+// ensureHeadlessService 确认headless Service存在，不存在时创建，created表示是否为本次新建
+func (s *statefulSet) ensureHeadlessService(client *kubernetes.Clientset, data *StatefulSetCreate, serviceName string) (created bool, err error) {
+	svc, err := client.CoreV1().Services(data.Namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
+	if err == nil {
+		if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+			return false, badRequest(fmt.Sprintf("Service %s已存在且不是headless Service", serviceName))
+		}
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		zap.L().Error(fmt.Sprintf("获取Service详情失败, %v", err.Error()))
+		return false, newAPIError("获取Service详情失败", err)
+	}
+	svc = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: data.Namespace,
+			Labels:    data.Label,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  data.Label,
+		},
+	}
+	if data.ContainerPort > 0 {
+		svc.Spec.Ports = []corev1.ServicePort{
+			{
+				Name:       "http",
+				Port:       data.ContainerPort,
+				TargetPort: intstr.FromInt(int(data.ContainerPort)),
+				Protocol:   corev1.ProtocolTCP,
+			},
+		}
+	}
+	if _, err = client.CoreV1().Services(data.Namespace).Create(context.TODO(), svc, metav1.CreateOptions{}); err != nil {
+		zap.L().Error(fmt.Sprintf("创建headless Service失败, %v", err.Error()))
+		return false, newAPIError("创建headless Service失败", err)
+	}
+	return true, nil
+}
+
 // GetStatefulSetNumPerNp 获取每个Namespace的StatefulSet的数量
-func (s *statefulSet) GetStatefulSetNumPerNp(client *kubernetes.Clientset) (StatefulSetSNps []*StatefulSetSNp, err error) {
+func (s *statefulSet) GetStatefulSetNumPerNp(cc *ClusterClient) (StatefulSetSNps []*StatefulSetSNp, err error) {
 	// 获取Namespace列表
-	namespaceList, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := cc.Cache.ListNamespaces()
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("获取Namespace列表失败, %v", err.Error()))
-		return nil, errors.New("获取Namespace列表失败, " + err.Error())
+		return nil, newAPIError("获取Namespace列表失败", err)
 	}
-	for _, namespace := range namespaceList.Items {
+	for _, namespace := range namespaceList {
 		// 获取Deployment列表
-		statefulSetList, err := client.AppsV1().StatefulSets(namespace.Name).List(context.TODO(), metav1.ListOptions{})
+		statefulSetList, err := cc.Cache.ListStatefulSets(namespace.Name)
 		if err != nil {
 			zap.L().Error(fmt.Sprintf("获取StatefulSet列表失败, %v", err.Error()))
-			return nil, errors.New("获取StatefulSet列表失败, " + err.Error())
+			return nil, newAPIError("获取StatefulSet列表失败", err)
 		}
 		// 组装数据
 		statefulNp := &StatefulSetSNp{
 			Namespace:      namespace.Name,
-			StatefulSetNum: len(statefulSetList.Items),
+			StatefulSetNum: len(statefulSetList),
 		}
 		// 添加数据到podsNps中
 		StatefulSetSNps = append(StatefulSetSNps, statefulNp)
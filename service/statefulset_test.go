@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestStatefulSetBuildValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(data *StatefulSetCreate)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(data *StatefulSetCreate) {}},
+		{name: "empty label", modify: func(data *StatefulSetCreate) { data.Label = nil }, wantErr: true},
+		{name: "health check without port", modify: func(data *StatefulSetCreate) { data.ContainerPort = 0 }, wantErr: true},
+		{name: "no health check without port", modify: func(data *StatefulSetCreate) { data.HealthCheck, data.ContainerPort = false, 0 }},
+		{name: "partition over replicas", modify: func(data *StatefulSetCreate) { data.Partition = 4 }, wantErr: true},
+		{name: "unknown pod management policy", modify: func(data *StatefulSetCreate) { data.PodManagementPolicy = "Random" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &StatefulSetCreate{
+				Name:          "web",
+				Namespace:     "default",
+				Replicas:      3,
+				Image:         "nginx:1.25",
+				Label:         map[string]string{"app": "web"},
+				ContainerPort: 80,
+				HealthCheck:   true,
+				HealthPath:    "/healthz",
+			}
+			tt.modify(data)
+			_, err := StatefulSet.build(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var apiErr *APIError
+			if err != nil && (!errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest) {
+				t.Errorf("build() error = %v, want 400 APIError", err)
+			}
+		})
+	}
+}
\ No newline at end of file
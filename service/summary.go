@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"time"
+)
+
+// 列表的返回视图，full返回完整的资源对象，summary只返回计算后的关键字段，完整对象通过详情接口获取
+const (
+	ViewFull    = "full"
+	ViewSummary = "summary"
+)
+
+// Summary 资源的精简视图，Status综合了conditions、容器状态等信息，Owner格式为 Kind/Name
+type Summary struct {
+	Cluster      string            `json:"cluster,omitempty"`
+	Kind         string            `json:"kind"`
+	Namespace    string            `json:"namespace"`
+	Name         string            `json:"name"`
+	Status       string            `json:"status"`
+	Ready        int32             `json:"ready"`
+	Desired      int32             `json:"desired"`
+	Images       []string          `json:"images,omitempty"`
+	Restarts     int               `json:"restarts"`
+	Node         string            `json:"node,omitempty"`
+	Owner        string            `json:"owner,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Age          string            `json:"age"`
+	CreationTime time.Time         `json:"creation_time"`
+}
+
+// SummaryResp 精简视图的列表返回内容
+type SummaryResp struct {
+	Total  int               `json:"total"`
+	Items  []*Summary        `json:"items"`
+	Errors map[string]string `json:"errors,omitempty"`
+	PageInfo
+}
+
+// CheckView 校验view参数，为空时使用full
+func CheckView(view string) error {
+	switch view {
+	case "", ViewFull, ViewSummary:
+		return nil
+	}
+	return errors.New(fmt.Sprintf("不支持的view: %s, 可选full、summary", view))
+}
+
+// Summary 以下为各列表返回内容的精简视图
+func (r *PodsResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Pod.toCells(r.Items))
+}
+
+func (r *DeploymentsResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Deployment.toCells(r.Items))
+}
+
+func (r *DaemonSetsResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, DaemonSet.toCells(r.Items))
+}
+
+func (r *StatefulSetsResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, StatefulSet.toCells(r.Items))
+}
+
+func (r *ServicesResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Service.toCells(r.Items))
+}
+
+func (r *IngressResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Ingress.toCells(r.Items))
+}
+
+func (r *configMapResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, ConfigMap.toCells(r.Items))
+}
+
+func (r *SecretResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Secret.toCells(r.Items))
+}
+
+func (r *PvcResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Pvc.toCells(r.Items))
+}
+
+func (r *pvResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Pv.toCells(r.Items))
+}
+
+func (r *nodeResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Node.toCells(r.Items))
+}
+
+func (r *namespaceResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Namespace.toCells(r.Items))
+}
+
+func (r *ResourceResp) Summary() *SummaryResp {
+	return newSummaryResp(r.Total, r.PageInfo, Generic.toCells(r.Items))
+}
+
+// Summary 跨集群列表的精简视图，保留各集群的错误信息
+func (r *MultiClusterResp) Summary() *SummaryResp {
+	resp := &SummaryResp{Total: r.Total, Items: make([]*Summary, 0, len(r.Items)), Errors: r.Errors, PageInfo: r.PageInfo}
+	for _, item := range r.Items {
+		summary := summarize(item.Item)
+		summary.Cluster = item.Cluster
+		resp.Items = append(resp.Items, summary)
+	}
+	return resp
+}
+
+func newSummaryResp(total int, pageInfo PageInfo, cells []DataCell) *SummaryResp {
+	resp := &SummaryResp{Total: total, Items: make([]*Summary, 0, len(cells)), PageInfo: pageInfo}
+	for _, cell := range cells {
+		resp.Items = append(resp.Items, summarize(cell))
+	}
+	return resp
+}
+
+// summarize 生成资源的精简视图，通用字段来自DataCell，其余字段按资源类型计算
+func summarize(cell DataCell) *Summary {
+	summary := &Summary{
+		Namespace:    cell.GetNamespace(),
+		Name:         cell.GetName(),
+		Status:       cell.GetStatus(),
+		Labels:       cell.GetLabels(),
+		Age:          duration.HumanDuration(time.Since(cell.GetCreation())),
+		CreationTime: cell.GetCreation(),
+	}
+	switch c := unwrapCell(cell).(type) {
+	case podCell:
+		podSummary(summary, corev1.Pod(c))
+	case deploymentCell:
+		deployment := appsv1.Deployment(c)
+		summary.Kind = KindDeployment
+		summary.Ready, summary.Desired = deployment.Status.ReadyReplicas, desiredReplicas(deployment.Spec.Replicas)
+		summary.Images = podImages(&deployment.Spec.Template.Spec)
+		summary.Owner = ownerString(&deployment.ObjectMeta)
+		summary.Status = deploymentStatus(&deployment)
+	case daemonSetCell:
+		daemonSet := appsv1.DaemonSet(c)
+		summary.Kind = KindDaemonSet
+		summary.Ready, summary.Desired = daemonSet.Status.NumberReady, daemonSet.Status.DesiredNumberScheduled
+		summary.Images = podImages(&daemonSet.Spec.Template.Spec)
+		summary.Owner = ownerString(&daemonSet.ObjectMeta)
+		summary.Status = rolloutStatus(daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.NumberAvailable)
+	case statefulSetCell:
+		statefulSet := appsv1.StatefulSet(c)
+		desired := desiredReplicas(statefulSet.Spec.Replicas)
+		summary.Kind = KindStatefulSet
+		summary.Ready, summary.Desired = statefulSet.Status.ReadyReplicas, desired
+		summary.Images = podImages(&statefulSet.Spec.Template.Spec)
+		summary.Owner = ownerString(&statefulSet.ObjectMeta)
+		summary.Status = rolloutStatus(desired, statefulSet.Status.UpdatedReplicas, statefulSet.Status.AvailableReplicas)
+	case nodeCell:
+		summary.Kind = KindNode
+		if c.Spec.Unschedulable {
+			summary.Status += ",SchedulingDisabled"
+		}
+	case serviceCell:
+		summary.Kind = KindService
+	case ingressCell:
+		summary.Kind = KindIngress
+	case configmapCell:
+		summary.Kind = KindConfigMap
+	case secretCell:
+		summary.Kind = KindSecret
+	case pvcCell:
+		summary.Kind = KindPvc
+	case pvCell:
+		summary.Kind = KindPv
+	case namespaceCell:
+		summary.Kind = KindNamespace
+	case unstructuredCell:
+		obj := unstructured.Unstructured(c)
+		summary.Kind = obj.GetKind()
+		summary.Owner = ownerString(&obj)
+	case SearchHit:
+		summary.Kind = c.Kind
+	}
+	return summary
+}
+
+// podSummary 计算pod的就绪容器数、重启次数及状态，状态的计算方式与kubectl get pod一致
+// init容器未全部成功时显示init容器的状态，如Init:0/2、Init:CrashLoopBackOff，此时不看主容器的状态
+func podSummary(summary *Summary, pod corev1.Pod) {
+	summary.Kind = KindPod
+	summary.Desired = int32(len(pod.Spec.Containers))
+	summary.Images = podImages(&pod.Spec)
+	summary.Node = pod.Spec.NodeName
+	if kind, name := podOwner(&pod); kind != "" {
+		summary.Owner = kind + "/" + name
+	}
+	summary.Status, summary.Ready, summary.Restarts = podStatus(&pod)
+}
+
+// podStatus 与kubectl get pod的STATUS、READY、RESTARTS列一致，Pod摘要及列表按状态过滤、按重启次数排序共用
+func podStatus(pod *corev1.Pod) (status string, ready int32, restarts int) {
+	status = string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, container := range pod.Status.InitContainerStatuses {
+		restarts += int(container.RestartCount)
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case container.State.Terminated != nil:
+			status = "Init:" + terminatedReason(container.State.Terminated)
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			status = "Init:" + container.State.Waiting.Reason
+		default:
+			status = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		restarts = 0
+		hasRunning := false
+		// 与kubectl一致倒序遍历，多个容器异常时显示第一个容器的状态
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			container := pod.Status.ContainerStatuses[i]
+			restarts += int(container.RestartCount)
+			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+				status = container.State.Waiting.Reason
+			} else if container.State.Terminated != nil {
+				status = terminatedReason(container.State.Terminated)
+			} else if container.Ready && container.State.Running != nil {
+				hasRunning = true
+				ready++
+			}
+		}
+		// 部分容器已正常退出、其余容器仍在运行时，按pod的Ready条件显示
+		if status == "Completed" && hasRunning {
+			status = "NotReady"
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+					status = string(corev1.PodRunning)
+				}
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil && pod.Status.Reason == "NodeLost" {
+		status = "Unknown"
+	} else if pod.DeletionTimestamp != nil {
+		status = "Terminating"
+	}
+	return status, ready, restarts
+}
+
+// terminatedReason 容器终止的原因，没有原因时显示退出信号或退出码
+func terminatedReason(terminated *corev1.ContainerStateTerminated) string {
+	if terminated.Reason != "" {
+		return terminated.Reason
+	}
+	if terminated.Signal != 0 {
+		return fmt.Sprintf("Signal:%d", terminated.Signal)
+	}
+	return fmt.Sprintf("ExitCode:%d", terminated.ExitCode)
+}
+
+// deploymentStatus 综合conditions计算deployment的状态
+func deploymentStatus(deployment *appsv1.Deployment) string {
+	if deployment.Spec.Paused {
+		return "Paused"
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue {
+			return "ReplicaFailure"
+		}
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse {
+			return "Failed"
+		}
+	}
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return "Progressing"
+	}
+	return rolloutStatus(desiredReplicas(deployment.Spec.Replicas), deployment.Status.UpdatedReplicas, deployment.Status.AvailableReplicas)
+}
+
+// rolloutStatus 根据副本数计算工作负载的状态，更新中的优先显示Progressing
+func rolloutStatus(desired, updated, available int32) string {
+	if updated < desired {
+		return "Progressing"
+	}
+	return replicasStatus(desired, available)
+}
+
+// ownerString 获取资源的controller，格式为 Kind/Name
+func ownerString(obj metav1.Object) string {
+	owner := metav1.GetControllerOfNoCopy(obj)
+	if owner == nil {
+		return ""
+	}
+	return owner.Kind + "/" + owner.Name
+}
\ No newline at end of file
@@ -0,0 +1,159 @@
+package service
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func stateWaiting(reason string) corev1.ContainerState {
+	return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+}
+
+func stateTerminated(reason string, exitCode int32) corev1.ContainerState {
+	return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}}
+}
+
+func stateRunning() corev1.ContainerState {
+	return corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+}
+
+func TestPodSummary(t *testing.T) {
+	now := metav1.Now()
+	twoInit := []corev1.Container{{Name: "init-a"}, {Name: "init-b"}}
+	twoMain := []corev1.Container{{Name: "app"}, {Name: "sidecar"}}
+	tests := []struct {
+		name         string
+		pod          corev1.Pod
+		wantStatus   string
+		wantReady    int32
+		wantRestarts int
+	}{
+		{
+			name: "running",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: twoMain},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Ready: true, State: stateRunning(), RestartCount: 1},
+						{Ready: true, State: stateRunning(), RestartCount: 2},
+					},
+				},
+			},
+			wantStatus: "Running", wantReady: 2, wantRestarts: 3,
+		},
+		{
+			name: "init container running shows progress",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInit, Containers: twoMain},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{State: stateTerminated("Completed", 0)},
+						{State: stateRunning()},
+					},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: stateWaiting("PodInitializing")},
+						{State: stateWaiting("PodInitializing")},
+					},
+				},
+			},
+			wantStatus: "Init:1/2",
+		},
+		{
+			name: "init container crash is not overwritten by PodInitializing",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInit, Containers: twoMain},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{State: stateWaiting("CrashLoopBackOff"), RestartCount: 4},
+						{State: stateWaiting("PodInitializing")},
+					},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: stateWaiting("PodInitializing")},
+						{State: stateWaiting("PodInitializing")},
+					},
+				},
+			},
+			wantStatus: "Init:CrashLoopBackOff", wantRestarts: 4,
+		},
+		{
+			name: "init container failed without reason",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInit, Containers: twoMain},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{{State: stateTerminated("", 2)}},
+				},
+			},
+			wantStatus: "Init:ExitCode:2",
+		},
+		{
+			name: "main container crash after init",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInit, Containers: twoMain},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{State: stateTerminated("Completed", 0), RestartCount: 1},
+						{State: stateTerminated("Completed", 0)},
+					},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: stateWaiting("CrashLoopBackOff"), RestartCount: 5},
+						{Ready: true, State: stateRunning()},
+					},
+				},
+			},
+			wantStatus: "CrashLoopBackOff", wantReady: 1, wantRestarts: 5,
+		},
+		{
+			name: "completed container with running sidecar",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: twoMain},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: stateTerminated("Completed", 0)},
+						{Ready: true, State: stateRunning()},
+					},
+				},
+			},
+			wantStatus: "NotReady", wantReady: 1,
+		},
+		{
+			name: "evicted",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: twoMain},
+				Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+			},
+			wantStatus: "Evicted",
+		},
+		{
+			name: "terminating",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+				Spec:       corev1.PodSpec{Containers: twoMain},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			wantStatus: "Terminating",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := &Summary{}
+			podSummary(summary, tt.pod)
+			if summary.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", summary.Status, tt.wantStatus)
+			}
+			if summary.Ready != tt.wantReady {
+				t.Errorf("ready = %v, want %v", summary.Ready, tt.wantReady)
+			}
+			if summary.Restarts != tt.wantRestarts {
+				t.Errorf("restarts = %v, want %v", summary.Restarts, tt.wantRestarts)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -8,7 +8,6 @@ import (
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/remotecommand"
 	"net/http"
 	"time"
@@ -34,13 +33,13 @@ func (t *terminal) WebsocketHandler(w http.ResponseWriter, r *http.Request) {
 		zap.L().Error("namespace、pod_name、container_name、cluster参数为空")
 		return
 	}
-	client, err := K8s.GetClient(cluster)
-	// 加载k8s配置
-	conf, err := clientcmd.BuildConfigFromFlags("", K8s.GetClusterConf(cluster))
+	// 从集群注册表获取client及rest.Config，不再每次从磁盘加载kubeconfig
+	cc, err := K8s.GetClusterClient(cluster)
 	if err != nil {
 		zap.L().Error(fmt.Sprintf("加载k8s配置失败, %v", err.Error()))
 		return
 	}
+	client, conf := cc.ClientSet, cc.RestConfig
 	//new一个TerminalSession类型的pty实例
 	pty, err := NewTerminalSession(w, r, nil)
 	if err != nil {
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"strconv"
+	"time"
+)
+
+var Workload workload
+
+type workload struct{}
+
+// RestartedAtAnnotation kubectl rollout restart使用的Pod模板annotation，值变化后按更新策略重建Pod
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// Restart 重启工作负载，与kubectl rollout restart一致，只修改Pod模板的annotation，不改动容器配置
+func (w *workload) Restart(client *kubernetes.Clientset, kind, namespace, name string) (err error) {
+	if kind == KindDeployment {
+		deploy, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Deployment详情失败, %v", err.Error()))
+			return newAPIError("获取Deployment详情失败", err)
+		}
+		// 暂停时修改Pod模板不会触发更新
+		if deploy.Spec.Paused {
+			return badRequest(fmt.Sprintf("Deployment %s已暂停, 恢复后才能重启", name))
+		}
+	}
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{RestartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	}
+	return w.patch(client, kind, namespace, name, "重启", patch)
+}
+
+// SetImage 修改工作负载中指定容器的镜像，只patch该容器的image，changeCause为空时记录为set image container=image
+func (w *workload) SetImage(client *kubernetes.Clientset, kind, namespace, name, container, image, changeCause string) (err error) {
+	if container == "" || image == "" {
+		return badRequest("容器名及镜像不能为空")
+	}
+	template, err := w.podTemplate(client, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+	// strategic merge patch按容器名合并，容器不存在时会新增容器，需要先确认
+	field := ""
+	for _, c := range template.Spec.Containers {
+		if c.Name == container {
+			field = "containers"
+		}
+	}
+	for _, c := range template.Spec.InitContainers {
+		if c.Name == container {
+			field = "initContainers"
+		}
+	}
+	if field == "" {
+		return badRequest(fmt.Sprintf("%s %s中不存在容器%s", kind, name, container))
+	}
+	if changeCause == "" {
+		changeCause = fmt.Sprintf("set image %s=%s", container, image)
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{ChangeCauseAnnotation: changeCause},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					field: []map[string]string{{"name": container, "image": image}},
+				},
+			},
+		},
+	}
+	return w.patch(client, kind, namespace, name, "修改镜像", patch)
+}
+
+// SetChangeCause 记录change-cause，Deployment下次更新时会带到新的ReplicaSet上，在历史版本中显示
+func (w *workload) SetChangeCause(client *kubernetes.Clientset, kind, namespace, name, changeCause string) (err error) {
+	if changeCause == "" {
+		return badRequest("change-cause不能为空")
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{ChangeCauseAnnotation: changeCause},
+		},
+	}
+	return w.patch(client, kind, namespace, name, "记录change-cause", patch)
+}
+
+// Pause 暂停滚动更新，暂停期间对Pod模板的修改不会触发更新，Deployment与kubectl rollout pause一致
+// StatefulSet将partition设为副本数，DaemonSet将更新策略改为OnDelete，原来的设置记录在annotation中，恢复时还原
+func (w *workload) Pause(client *kubernetes.Clientset, kind, namespace, name string) (err error) {
+	return w.setPaused(client, kind, namespace, name, true)
+}
+
+// Resume 恢复滚动更新，暂停期间的修改会按更新策略生效
+func (w *workload) Resume(client *kubernetes.Clientset, kind, namespace, name string) (err error) {
+	return w.setPaused(client, kind, namespace, name, false)
+}
+
+// 暂停StatefulSet、DaemonSet时记录原来的partition及更新策略，恢复时还原
+const (
+	PausedPartitionAnnotation = "k8s-manager/paused-partition"
+	PausedStrategyAnnotation  = "k8s-manager/paused-update-strategy"
+)
+
+// setPaused Deployment修改spec.paused，StatefulSet、DaemonSet读取后修改更新策略，resourceVersion冲突时重新读取后重试
+func (w *workload) setPaused(client *kubernetes.Clientset, kind, namespace, name string, paused bool) error {
+	action := "恢复滚动更新"
+	if paused {
+		action = "暂停滚动更新"
+	}
+	apps := client.AppsV1()
+	switch kind {
+	case KindDeployment:
+		patch := map[string]interface{}{
+			"spec": map[string]interface{}{"paused": paused},
+		}
+		return w.patch(client, kind, namespace, name, action, patch)
+	case KindStatefulSet:
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			sts, err := apps.StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				zap.L().Error(fmt.Sprintf("获取StatefulSet详情失败, %v", err.Error()))
+				return newAPIError("获取StatefulSet详情失败", err)
+			}
+			if err := setStatefulSetPaused(sts, paused); err != nil {
+				return err
+			}
+			if _, err := apps.StatefulSets(namespace).Update(context.TODO(), sts, metav1.UpdateOptions{}); err != nil {
+				zap.L().Error(fmt.Sprintf("%s失败, %v", action, err.Error()))
+				return newAPIError(action+"失败", err)
+			}
+			return nil
+		})
+	case KindDaemonSet:
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			ds, err := apps.DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				zap.L().Error(fmt.Sprintf("获取DaemonSet详情失败, %v", err.Error()))
+				return newAPIError("获取DaemonSet详情失败", err)
+			}
+			if err := setDaemonSetPaused(ds, paused); err != nil {
+				return err
+			}
+			if _, err := apps.DaemonSets(namespace).Update(context.TODO(), ds, metav1.UpdateOptions{}); err != nil {
+				zap.L().Error(fmt.Sprintf("%s失败, %v", action, err.Error()))
+				return newAPIError(action+"失败", err)
+			}
+			return nil
+		})
+	}
+	return w.kindError(kind)
+}
+
+// setStatefulSetPaused 暂停时记录原来的partition并设为副本数，序号都小于副本数，不会再更新Pod，恢复时还原partition
+// OnDelete策略本身只在手动删除Pod时更新，不需要暂停
+func setStatefulSetPaused(sts *appsv1.StatefulSet, paused bool) error {
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return badRequest(fmt.Sprintf("StatefulSet %s的更新策略为OnDelete, 手动删除Pod时才会更新, 不需要暂停及恢复", sts.Name))
+	}
+	previous, isPaused := sts.Annotations[PausedPartitionAnnotation]
+	if paused && isPaused {
+		return badRequest(fmt.Sprintf("StatefulSet %s已暂停", sts.Name))
+	}
+	if !paused && !isPaused {
+		return badRequest(fmt.Sprintf("StatefulSet %s未暂停", sts.Name))
+	}
+	if sts.Spec.UpdateStrategy.RollingUpdate == nil {
+		sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{}
+	}
+	rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate
+	if paused {
+		var partition int32
+		if rollingUpdate.Partition != nil {
+			partition = *rollingUpdate.Partition
+		}
+		metav1.SetMetaDataAnnotation(&sts.ObjectMeta, PausedPartitionAnnotation, strconv.FormatInt(int64(partition), 10))
+		replicas := desiredReplicas(sts.Spec.Replicas)
+		rollingUpdate.Partition = &replicas
+		return nil
+	}
+	// annotation被修改成非法的值时全部更新
+	partition, err := strconv.ParseInt(previous, 10, 32)
+	if err != nil || partition < 0 {
+		partition = 0
+	}
+	restored := int32(partition)
+	rollingUpdate.Partition = &restored
+	delete(sts.Annotations, PausedPartitionAnnotation)
+	return nil
+}
+
+// setDaemonSetPaused 暂停时记录原来的更新策略并改为OnDelete，之后只有手动删除的Pod才会更新，恢复时还原更新策略
+func setDaemonSetPaused(ds *appsv1.DaemonSet, paused bool) error {
+	previous, isPaused := ds.Annotations[PausedStrategyAnnotation]
+	if paused && isPaused {
+		return badRequest(fmt.Sprintf("DaemonSet %s已暂停", ds.Name))
+	}
+	if !paused && !isPaused {
+		return badRequest(fmt.Sprintf("DaemonSet %s未暂停", ds.Name))
+	}
+	if paused {
+		if ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			return badRequest(fmt.Sprintf("DaemonSet %s的更新策略为OnDelete, 手动删除Pod时才会更新, 不需要暂停及恢复", ds.Name))
+		}
+		strategy, err := json.Marshal(ds.Spec.UpdateStrategy)
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("Json序列化失败, %v", err.Error()))
+			return errors.New("Json序列化失败, " + err.Error())
+		}
+		metav1.SetMetaDataAnnotation(&ds.ObjectMeta, PausedStrategyAnnotation, string(strategy))
+		ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+		return nil
+	}
+	// annotation被修改成非法的值时使用默认的RollingUpdate，maxUnavailable等由apiserver设置默认值
+	strategy := appsv1.DaemonSetUpdateStrategy{}
+	if err := json.Unmarshal([]byte(previous), &strategy); err != nil || strategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		strategy = appsv1.DaemonSetUpdateStrategy{}
+	}
+	if strategy.Type == "" {
+		strategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+	}
+	ds.Spec.UpdateStrategy = strategy
+	delete(ds.Annotations, PausedStrategyAnnotation)
+	return nil
+}
+
+// podTemplate 获取工作负载的Pod模板
+func (w *workload) podTemplate(client *kubernetes.Clientset, kind, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	switch kind {
+	case KindDeployment:
+		deploy, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取Deployment详情失败, %v", err.Error()))
+			return nil, newAPIError("获取Deployment详情失败", err)
+		}
+		return &deploy.Spec.Template, nil
+	case KindDaemonSet:
+		ds, err := client.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取DaemonSet详情失败, %v", err.Error()))
+			return nil, newAPIError("获取DaemonSet详情失败", err)
+		}
+		return &ds.Spec.Template, nil
+	case KindStatefulSet:
+		sts, err := client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			zap.L().Error(fmt.Sprintf("获取StatefulSet详情失败, %v", err.Error()))
+			return nil, newAPIError("获取StatefulSet详情失败", err)
+		}
+		return &sts.Spec.Template, nil
+	}
+	return nil, w.kindError(kind)
+}
+
+// patch 以strategic merge patch修改工作负载，patch只包含要修改的字段，冲突时直接重试，action用于错误信息
+func (w *workload) patch(client *kubernetes.Clientset, kind, namespace, name, action string, patch interface{}) error {
+	if kind != KindDeployment && kind != KindDaemonSet && kind != KindStatefulSet {
+		return w.kindError(kind)
+	}
+	patchByte, err := json.Marshal(patch)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("Json序列化失败, %v", err.Error()))
+		return errors.New("Json序列化失败, " + err.Error())
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var err error
+		switch kind {
+		case KindDeployment:
+			_, err = client.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patchByte, metav1.PatchOptions{})
+		case KindDaemonSet:
+			_, err = client.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patchByte, metav1.PatchOptions{})
+		case KindStatefulSet:
+			_, err = client.AppsV1().StatefulSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patchByte, metav1.PatchOptions{})
+		}
+		return err
+	})
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("%s%s失败, %v", kind, action, err.Error()))
+		return newAPIError(fmt.Sprintf("%s%s失败", kind, action), err)
+	}
+	return nil
+}
+
+// kindError 不支持的工作负载类型
+func (w *workload) kindError(kind string) error {
+	return badRequest(fmt.Sprintf("不支持的资源类型: %s, 可选Deployment、StatefulSet、DaemonSet", kind))
+}
\ No newline at end of file
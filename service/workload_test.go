@@ -0,0 +1,88 @@
+package service
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+)
+
+func TestSetStatefulSetPaused(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(5),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(2)},
+			},
+		},
+	}
+	if err := setStatefulSetPaused(sts, false); err == nil {
+		t.Fatalf("resume before pause expected error")
+	}
+	if err := setStatefulSetPaused(sts, true); err != nil {
+		t.Fatalf("pause error = %v", err)
+	}
+	if got := *sts.Spec.UpdateStrategy.RollingUpdate.Partition; got != 5 {
+		t.Errorf("paused partition = %v, want 5", got)
+	}
+	if got := sts.Annotations[PausedPartitionAnnotation]; got != "2" {
+		t.Errorf("annotation = %q, want 2", got)
+	}
+	if err := setStatefulSetPaused(sts, true); err == nil {
+		t.Errorf("pause twice expected error")
+	}
+	if err := setStatefulSetPaused(sts, false); err != nil {
+		t.Fatalf("resume error = %v", err)
+	}
+	if got := *sts.Spec.UpdateStrategy.RollingUpdate.Partition; got != 2 {
+		t.Errorf("resumed partition = %v, want 2", got)
+	}
+	if _, ok := sts.Annotations[PausedPartitionAnnotation]; ok {
+		t.Errorf("annotation not removed after resume")
+	}
+
+	onDelete := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}}}
+	if err := setStatefulSetPaused(onDelete, true); err == nil {
+		t.Errorf("pause OnDelete StatefulSet expected error")
+	}
+}
+
+func TestSetDaemonSetPaused(t *testing.T) {
+	maxUnavailable := intstr.FromString("20%")
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent"},
+		Spec: appsv1.DaemonSetSpec{
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+			},
+		},
+	}
+	if err := setDaemonSetPaused(ds, true); err != nil {
+		t.Fatalf("pause error = %v", err)
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType || ds.Spec.UpdateStrategy.RollingUpdate != nil {
+		t.Errorf("paused strategy = %+v, want OnDelete", ds.Spec.UpdateStrategy)
+	}
+	if err := setDaemonSetPaused(ds, false); err != nil {
+		t.Fatalf("resume error = %v", err)
+	}
+	strategy := ds.Spec.UpdateStrategy
+	if strategy.Type != appsv1.RollingUpdateDaemonSetStrategyType || strategy.RollingUpdate == nil || strategy.RollingUpdate.MaxUnavailable.String() != "20%" {
+		t.Errorf("resumed strategy = %+v, want RollingUpdate with maxUnavailable 20%%", strategy)
+	}
+	if _, ok := ds.Annotations[PausedStrategyAnnotation]; ok {
+		t.Errorf("annotation not removed after resume")
+	}
+
+	ds.Annotations = map[string]string{PausedStrategyAnnotation: "not json"}
+	ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+	if err := setDaemonSetPaused(ds, false); err != nil {
+		t.Fatalf("resume with invalid annotation error = %v", err)
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		t.Errorf("resumed type = %v, want RollingUpdate", ds.Spec.UpdateStrategy.Type)
+	}
+}
\ No newline at end of file